@@ -0,0 +1,156 @@
+package main
+
+import (
+	"expvar"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// slowEnqueue - после какого ожидания места в очереди бот пишет в лог о перегрузке.
+const slowEnqueue = time.Second
+
+type updateJob struct {
+	key    string
+	update tgbotapi.Update
+}
+
+// updateDispatcher обрабатывает обновления пулом воркеров. Обновления одного чата (или одного
+// inline-сообщения) обрабатываются строго по очереди одним воркером за раз, разных чатов - параллельно,
+// поэтому медленный запрос задерживает только свой чат. Очередь ограничена: когда она заполнена,
+// Dispatch ждёт, и бот перестаёт забирать новые обновления у Telegram.
+type updateDispatcher struct {
+	handle  func(tgbotapi.Update)
+	slots   chan struct{}
+	ready   chan updateJob
+	metrics *expvar.Map
+
+	mu sync.Mutex
+	// pending - обновления чатов, которые сейчас обрабатываются; ключ есть, пока чат занят воркером
+	pending map[string][]tgbotapi.Update
+}
+
+// newUpdateDispatcher запускает workers воркеров с общей очередью на queueSize обновлений
+// и публикует метрики в expvar под именем bot_updates.
+func newUpdateDispatcher(workers, queueSize int, handle func(tgbotapi.Update)) *updateDispatcher {
+	return startUpdateDispatcher(workers, queueSize, expvar.NewMap("bot_updates"), handle)
+}
+
+// startUpdateDispatcher запускает воркеры и пишет метрики в metrics: имя в expvar можно
+// зарегистрировать только один раз на процесс.
+func startUpdateDispatcher(workers, queueSize int, metrics *expvar.Map, handle func(tgbotapi.Update)) *updateDispatcher {
+	d := &updateDispatcher{
+		handle:  handle,
+		slots:   make(chan struct{}, queueSize),
+		ready:   make(chan updateJob, queueSize),
+		metrics: metrics,
+		pending: map[string][]tgbotapi.Update{},
+	}
+
+	d.metrics.Set("workers", expvar.Func(func() any { return workers }))
+	d.metrics.Set("queue_capacity", expvar.Func(func() any { return queueSize }))
+	d.metrics.Set("queued", expvar.Func(func() any { return len(d.slots) }))
+	d.metrics.Set("busy_chats", expvar.Func(func() any {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		return len(d.pending)
+	}))
+
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// Dispatch ставит обновление в очередь. Если очередь заполнена, ждёт освобождения места.
+func (d *updateDispatcher) Dispatch(update tgbotapi.Update) {
+	select {
+	case d.slots <- struct{}{}:
+	default:
+		start := time.Now()
+		d.slots <- struct{}{}
+		waited := time.Since(start)
+
+		d.metrics.Add("blocked", 1)
+		d.metrics.Add("blocked_ms", waited.Milliseconds())
+		if waited >= slowEnqueue {
+			log.Printf("Очередь обновлений заполнена, обновление %d ждало %s", update.UpdateID, waited)
+		}
+	}
+	d.metrics.Add("received", 1)
+
+	key := updateChatKey(update)
+	if key != "" {
+		d.mu.Lock()
+		if queue, busy := d.pending[key]; busy {
+			d.pending[key] = append(queue, update)
+			d.mu.Unlock()
+			return
+		}
+		d.pending[key] = nil
+		d.mu.Unlock()
+	}
+
+	d.ready <- updateJob{key: key, update: update}
+}
+
+// work берёт чат из общей очереди и обрабатывает его обновления, пока они не кончатся.
+func (d *updateDispatcher) work() {
+	for job := range d.ready {
+		for {
+			<-d.slots
+			d.process(job.update)
+
+			if job.key == "" {
+				break
+			}
+
+			d.mu.Lock()
+			queue := d.pending[job.key]
+			if len(queue) == 0 {
+				delete(d.pending, job.key)
+				d.mu.Unlock()
+				break
+			}
+			job.update = queue[0]
+			d.pending[job.key] = queue[1:]
+			d.mu.Unlock()
+		}
+	}
+}
+
+func (d *updateDispatcher) process(update tgbotapi.Update) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			d.metrics.Add("panics", 1)
+			log.Printf("Паника при обработке обновления %d: %v", update.UpdateID, r)
+		}
+		d.metrics.Add("processed", 1)
+		d.metrics.Add("handle_ms", time.Since(start).Milliseconds())
+	}()
+
+	d.handle(update)
+}
+
+// updateChatKey возвращает ключ, по которому упорядочиваются обновления: чат сообщения или кнопки,
+// inline-сообщение для его кнопок и выбора inline-результата, пользователя для inline-запроса.
+// Пустой ключ означает, что порядок обновления не важен.
+func updateChatKey(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil:
+		return strconv.FormatInt(update.Message.Chat.ID, 10)
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return strconv.FormatInt(update.CallbackQuery.Message.Chat.ID, 10)
+	case update.CallbackQuery != nil && update.CallbackQuery.InlineMessageID != "":
+		return "inline:" + update.CallbackQuery.InlineMessageID
+	case update.ChosenInlineResult != nil && update.ChosenInlineResult.InlineMessageID != "":
+		return "inline:" + update.ChosenInlineResult.InlineMessageID
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return "user:" + strconv.FormatInt(update.InlineQuery.From.ID, 10)
+	}
+	return ""
+}
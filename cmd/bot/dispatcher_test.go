@@ -0,0 +1,165 @@
+package main
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func chatUpdate(id int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}}}
+}
+
+func metricValue(metrics *expvar.Map, name string) int64 {
+	if v, ok := metrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func waitStarted(t *testing.T, started <-chan int) int {
+	t.Helper()
+	select {
+	case id := <-started:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("обновление не начало обрабатываться")
+		return 0
+	}
+}
+
+func assertNotStarted(t *testing.T, started <-chan int) {
+	t.Helper()
+	select {
+	case id := <-started:
+		t.Fatalf("обновление %d начало обрабатываться раньше времени", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUpdateChatKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   string
+	}{
+		{"сообщение", chatUpdate(1, 42), "42"},
+		{
+			"кнопка в чате",
+			tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -7}}}},
+			"-7",
+		},
+		{
+			"кнопка inline-сообщения",
+			tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{InlineMessageID: "abc"}},
+			"inline:abc",
+		},
+		{
+			"выбор inline-результата",
+			tgbotapi.Update{ChosenInlineResult: &tgbotapi.ChosenInlineResult{InlineMessageID: "abc"}},
+			"inline:abc",
+		},
+		{
+			"inline-запрос",
+			tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 5}}},
+			"user:5",
+		},
+		{"без ключа", tgbotapi.Update{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updateChatKey(tt.update); got != tt.want {
+				t.Errorf("updateChatKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcherKeepsChatOrder(t *testing.T) {
+	started := make(chan int, 1)
+	release := make(chan struct{})
+	d := startUpdateDispatcher(4, 16, new(expvar.Map).Init(), func(update tgbotapi.Update) {
+		started <- update.UpdateID
+		<-release
+	})
+
+	for id := 1; id <= 3; id++ {
+		d.Dispatch(chatUpdate(id, 42))
+	}
+
+	// свободных воркеров хватает, но следующее обновление чата ждёт, пока не закончится предыдущее
+	for want := 1; want <= 3; want++ {
+		if id := waitStarted(t, started); id != want {
+			t.Fatalf("обработано обновление %d, want %d", id, want)
+		}
+		assertNotStarted(t, started)
+		release <- struct{}{}
+	}
+}
+
+func TestDispatcherRunsChatsConcurrently(t *testing.T) {
+	started := make(chan int, 2)
+	release := make(chan struct{})
+	defer close(release)
+	d := startUpdateDispatcher(2, 16, new(expvar.Map).Init(), func(update tgbotapi.Update) {
+		started <- update.UpdateID
+		<-release
+	})
+
+	d.Dispatch(chatUpdate(1, 1))
+	d.Dispatch(chatUpdate(2, 2))
+
+	// оба обновления начались, хотя ни одно ещё не закончилось
+	got := map[int]bool{waitStarted(t, started): true, waitStarted(t, started): true}
+	if !got[1] || !got[2] {
+		t.Errorf("начаты обновления %v, want 1 и 2", got)
+	}
+}
+
+func TestDispatcherBlocksWhenQueueIsFull(t *testing.T) {
+	started := make(chan int, 4)
+	release := make(chan struct{})
+	metrics := new(expvar.Map).Init()
+	d := startUpdateDispatcher(1, 2, metrics, func(update tgbotapi.Update) {
+		started <- update.UpdateID
+		<-release
+	})
+
+	// воркер занят первым обновлением, второе и третье заполняют очередь
+	d.Dispatch(chatUpdate(1, 1))
+	waitStarted(t, started)
+	d.Dispatch(chatUpdate(2, 2))
+	d.Dispatch(chatUpdate(3, 3))
+
+	dispatched := make(chan struct{})
+	go func() {
+		d.Dispatch(chatUpdate(4, 4))
+		close(dispatched)
+	}()
+
+	select {
+	case <-dispatched:
+		t.Fatal("Dispatch не ждал места в заполненной очереди")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := metricValue(metrics, "blocked"); got != 0 {
+		t.Errorf("blocked = %d до освобождения места, want 0", got)
+	}
+
+	close(release)
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch не дождался места в очереди")
+	}
+
+	if got := metricValue(metrics, "blocked"); got != 1 {
+		t.Errorf("blocked = %d, want 1", got)
+	}
+	if got := metricValue(metrics, "received"); got != 4 {
+		t.Errorf("received = %d, want 4", got)
+	}
+}
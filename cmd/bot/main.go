@@ -2,30 +2,48 @@ package main
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
 )
 
 type IncomingMessage struct {
-	UserID   string  `json:"userId"`
-	UserName string  `json:"userName,omitempty"`
-	Text     *string `json:"text,omitempty"`
-	Action   *string `json:"action,omitempty"`
+	UserID          string  `json:"userId"`
+	UserName        string  `json:"userName,omitempty"`
+	Username        string  `json:"username,omitempty"`
+	LanguageCode    string  `json:"languageCode,omitempty"`
+	ChatID          string  `json:"chatId,omitempty"`
+	ChatType        string  `json:"chatType,omitempty"`
+	InlineMessageID string  `json:"inlineMessageId,omitempty"`
+	Text            *string `json:"text,omitempty"`
+	Action          *string `json:"action,omitempty"`
 }
 
+// OutgoingMessage - сообщение от бэкенда. Сообщение с непустым Ref заменяет ранее отправленное
+// в тот же чат сообщение с этим Ref (например, доску игры), а не отправляется заново.
+// Layout - число кнопок в каждом ряду клавиатуры. ChatID задаёт чат для сообщения (например,
+// групповой чат с общей доской), без него сообщение уходит в личный чат пользователя UserID.
+// Сообщение с InlineMessageID заменяет это inline-сообщение.
 type OutgoingMessage struct {
-	UserID  string   `json:"userId"`
-	Text    string   `json:"text"`
-	Buttons []Button `json:"buttons"`
+	UserID          string   `json:"userId"`
+	ChatID          string   `json:"chatId,omitempty"`
+	InlineMessageID string   `json:"inlineMessageId,omitempty"`
+	Text            string   `json:"text"`
+	Buttons         []Button `json:"buttons"`
+	Layout          []int    `json:"layout,omitempty"`
+	Ref             string   `json:"ref,omitempty"`
 }
 
 type OutgoingMessages struct {
@@ -37,6 +55,11 @@ type Button struct {
 	Action string `json:"action"`
 }
 
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 func init() {
 	if err := godotenv.Load(); err != nil {
 		log.Printf("файл .env не найден: %v", err)
@@ -54,7 +77,13 @@ func main() {
 		serviceURL = "http://localhost:8080/command"
 	}
 
-	bot, err := tgbotapi.NewBotAPI(botToken)
+	// TELEGRAM_API_URL позволяет направить бота на локальный сервер Bot API, например тестовый
+	apiEndpoint := tgbotapi.APIEndpoint
+	if apiURL := os.Getenv("TELEGRAM_API_URL"); apiURL != "" {
+		apiEndpoint = strings.TrimSuffix(apiURL, "/") + "/bot%s/%s"
+	}
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(botToken, apiEndpoint)
 	if err != nil {
 		log.Fatalf("Ошибка инициализации бота: %v", err)
 	}
@@ -62,99 +91,311 @@ func main() {
 	log.Printf("Бот запущен: @%s", bot.Self.UserName)
 	log.Printf("Backend URL: %s", serviceURL)
 
-	u := tgbotapi.NewUpdate(-1)
-	u.Timeout = 60
+	deliveryAddr := os.Getenv("DELIVERY_ADDR")
+	if deliveryAddr == "" {
+		deliveryAddr = "127.0.0.1:8081"
+	}
+	deliverySecret := os.Getenv("DELIVERY_SECRET")
+	if deliverySecret == "" && !isLoopbackAddr(deliveryAddr) {
+		log.Fatalf("Не указан DELIVERY_SECRET: без него приём уведомлений можно слушать только на localhost, а не на %s", deliveryAddr)
+	}
+	go serveDeliveries(bot, deliveryAddr, deliverySecret)
+
+	updates, err := receiveUpdates(bot, os.Getenv("BOT_MODE"))
+	if err != nil {
+		log.Fatalf("Ошибка получения обновлений: %v", err)
+	}
 
-	updates := bot.GetUpdatesChan(u)
+	dispatcher := newUpdateDispatcher(getEnvInt("BOT_WORKERS", 8), getEnvInt("BOT_QUEUE_SIZE", 256), func(update tgbotapi.Update) {
+		handleUpdate(bot, update, serviceURL)
+	})
 
 	for update := range updates {
-		if update.Message != nil {
-			handleMessage(bot, update.Message, serviceURL)
-		} else if update.CallbackQuery != nil {
-			handleCallback(bot, update.CallbackQuery, serviceURL)
+		dispatcher.Dispatch(update)
+	}
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Неверное значение %s=%q, используется %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// receiveUpdates возвращает поток обновлений в выбранном режиме: long polling (по умолчанию) или webhook.
+// Обновления из обоих режимов обрабатываются одинаково.
+func receiveUpdates(bot *tgbotapi.BotAPI, mode string) (tgbotapi.UpdatesChannel, error) {
+	switch mode {
+	case "", "polling":
+		// пока зарегистрирован webhook, Telegram не отдаёт обновления через getUpdates
+		if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			return nil, fmt.Errorf("ошибка удаления webhook: %w", err)
 		}
+
+		u := tgbotapi.NewUpdate(-1)
+		u.Timeout = 60
+		log.Printf("Получение обновлений через long polling")
+		return bot.GetUpdatesChan(u), nil
+
+	case "webhook":
+		cfg, err := webhookConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return listenForWebhook(bot, cfg)
 	}
+
+	return nil, fmt.Errorf("неизвестный режим BOT_MODE: %s", mode)
 }
 
+func handleUpdate(bot *tgbotapi.BotAPI, update tgbotapi.Update, serviceURL string) {
+	if update.Message != nil && update.Message.From != nil {
+		handleMessage(bot, update.Message, serviceURL)
+	} else if update.CallbackQuery != nil {
+		handleCallback(bot, update.CallbackQuery, serviceURL)
+	} else if update.InlineQuery != nil {
+		handleInlineQuery(bot, update.InlineQuery)
+	} else if update.ChosenInlineResult != nil {
+		handleChosenInlineResult(bot, update.ChosenInlineResult, serviceURL)
+	}
+}
+
+// handleMessage передаёт текст бэкенду от имени отправителя. В группах бот отвечает только на команды,
+// и команда вида /new@botname, адресованная другому боту, пропускается.
 func handleMessage(bot *tgbotapi.BotAPI, message *tgbotapi.Message, serviceURL string) {
-	userID := fmt.Sprintf("chat_%d", message.Chat.ID)
-	userName := getUserName(message.From)
-	text := message.Text
+	text, ok := normalizeCommand(message.Text, bot.Self.UserName)
+	if !ok || (!message.Chat.IsPrivate() && !strings.HasPrefix(text, "/")) {
+		return
+	}
 
-	response, status := sendToBackend(serviceURL, userID, userName, text, nil)
+	msg := newIncomingMessage(message.From, message.Chat)
+	msg.Text = &text
+	response, _ := sendToBackend(serviceURL, msg)
 	sendResponse(bot, message.Chat.ID, response)
+}
 
-	if strings.HasPrefix(text, "/join ") || strings.HasPrefix(text, "/move ") {
-		if successResponse, ok := response.(OutgoingMessage); ok {
-			if status == http.StatusOK && !strings.Contains(successResponse.Text, "ошибка") && !strings.Contains(successResponse.Text, "не ваш ход") && !strings.Contains(successResponse.Text, "не активна") {
-				log.Printf("Отправляем push-уведомления для успешной команды: %s", text)
-				notifyURL := strings.Replace(serviceURL, "/command", "/notify", 1)
-				notifyResponse, _ := sendToBackend(notifyURL, userID, userName, text, nil)
-
-				if messages, ok := notifyResponse.(OutgoingMessages); ok {
-					log.Printf("Получено %d push-уведомлений", len(messages.Messages))
-					for _, msg := range messages.Messages {
-						targetChatID := extractChatIDFromUserID(msg.UserID)
-						if targetChatID != 0 && targetChatID != message.Chat.ID {
-							log.Printf("Отправляем push-уведомление игроку %d", targetChatID)
-							sendSingleMessage(bot, targetChatID, msg)
-						}
-					}
-				} else {
-					log.Printf("Не удалось получить push-уведомления для команды: %s", text)
-				}
-			} else {
-				log.Printf("Команда завершилась ошибкой, push-уведомления не отправляем: %s", text)
-			}
-		}
+// normalizeCommand убирает имя бота из команды (/new@botname rated -> /new rated).
+// Возвращает false, если команда адресована другому боту.
+func normalizeCommand(text, botName string) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return text, true
+	}
+
+	command, args, _ := strings.Cut(text, " ")
+	command, mention, found := strings.Cut(command, "@")
+	if found && !strings.EqualFold(mention, botName) {
+		return "", false
 	}
+
+	if args != "" {
+		return command + " " + args, true
+	}
+	return command, true
+}
+
+func telegramUserID(id int64) string {
+	return fmt.Sprintf("user_%d", id)
 }
 
+// noopAction - данные кнопок без действия (например, занятых клеток доски). Telegram требует
+// данные у каждой inline-кнопки, а нажатие на такую кнопку бот просто подтверждает.
+const noopAction = "noop"
+
+// handleCallback передаёт бэкенду нажатие кнопки. Кнопки inline-сообщения приходят без Message:
+// бот не знает чат, в котором оно отправлено, и ответ бэкенда заменяет само inline-сообщение.
 func handleCallback(bot *tgbotapi.BotAPI, callback *tgbotapi.CallbackQuery, serviceURL string) {
-	userID := fmt.Sprintf("chat_%d", callback.Message.Chat.ID)
-	userName := getUserName(callback.From)
+	if callback.Data == noopAction {
+		bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+
+	if callback.Message == nil && callback.InlineMessageID == "" {
+		bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+
+	var chat *tgbotapi.Chat
+	var chatID int64
+	if callback.Message != nil {
+		chat = callback.Message.Chat
+		chatID = chat.ID
+	}
+
 	action := callback.Data
+	msg := newIncomingMessage(callback.From, chat)
+	msg.InlineMessageID = callback.InlineMessageID
+	msg.Action = &action
+
+	response, status := sendToBackend(serviceURL, msg)
+
+	// ошибку нажатия (например, «не ваш ход» на общей доске) видит только нажавший, а не весь чат
+	if errMsg, ok := response.(OutgoingMessage); ok && status != http.StatusOK {
+		alert := tgbotapi.NewCallback(callback.ID, errMsg.Text)
+		alert.ShowAlert = true
+		bot.Request(alert)
+		return
+	}
 
-	response, status := sendToBackend(serviceURL, userID, userName, "", &action)
-	sendResponse(bot, callback.Message.Chat.ID, response)
-
-	if strings.HasPrefix(action, "/join ") || strings.HasPrefix(action, "/move ") {
-		if successResponse, ok := response.(OutgoingMessage); ok {
-			if status == http.StatusOK && !strings.Contains(successResponse.Text, "ошибка") && !strings.Contains(successResponse.Text, "не ваш ход") && !strings.Contains(successResponse.Text, "не активна") {
-				log.Printf("Отправляем push-уведомления для успешного действия: %s", action)
-				notifyURL := strings.Replace(serviceURL, "/command", "/notify", 1)
-				notifyResponse, _ := sendToBackend(notifyURL, userID, userName, "", &action)
-
-				if messages, ok := notifyResponse.(OutgoingMessages); ok {
-					log.Printf("Получено %d push-уведомлений для действия", len(messages.Messages))
-					for _, msg := range messages.Messages {
-						targetChatID := extractChatIDFromUserID(msg.UserID)
-						if targetChatID != 0 && targetChatID != callback.Message.Chat.ID {
-							log.Printf("Отправляем push-уведомление игроку %d для действия", targetChatID)
-							sendSingleMessage(bot, targetChatID, msg)
-						}
-					}
-				} else {
-					log.Printf("Не удалось получить push-уведомления для действия: %s", action)
-				}
-			} else {
-				log.Printf("Действие завершилось ошибкой, push-уведомления не отправляем: %s", action)
-			}
+	sendResponse(bot, chatID, response)
+	bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// inlineResultCommands - результаты inline-режима и команды, которыми бэкенд создаёт игру
+// после того, как пользователь выбрал результат.
+var inlineResultCommands = map[string]string{
+	"casual": "/new casual",
+	"rated":  "/new rated",
+}
+
+// handleInlineQuery предлагает отправить приглашение в игру в чат, где набрали @botname.
+// У приглашения должна быть клавиатура, иначе Telegram не сообщит его inline_message_id.
+func handleInlineQuery(bot *tgbotapi.BotAPI, query *tgbotapi.InlineQuery) {
+	name := getUserName(query.From)
+	joinKeyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✋ Присоединиться", "/join"),
+	))
+
+	casual := tgbotapi.NewInlineQueryResultArticle("casual", "🎲 Сыграть в крестики-нолики",
+		fmt.Sprintf("🎮 %s приглашает сыграть в крестики-нолики. Нажмите «Присоединиться», чтобы сыграть.", name))
+	casual.Description = "Обычная игра: соперником станет первый, кто нажмёт «Присоединиться»"
+	casual.ReplyMarkup = &joinKeyboard
+
+	rated := tgbotapi.NewInlineQueryResultArticle("rated", "🏆 Рейтинговая игра",
+		fmt.Sprintf("🏆 %s приглашает сыграть в рейтинговую игру в крестики-нолики. Нажмите «Присоединиться», чтобы сыграть.", name))
+	rated.Description = "Результат изменит рейтинг обоих игроков"
+	rated.ReplyMarkup = &joinKeyboard
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []interface{}{casual, rated},
+		IsPersonal:    true,
+	}
+	if _, err := bot.Request(answer); err != nil {
+		log.Printf("Ошибка ответа на inline-запрос: %v", err)
+	}
+}
+
+// handleChosenInlineResult создаёт игру, привязанную к отправленному приглашению, и заменяет приглашение
+// доской. Выбор результата приходит, только если боту включён inline feedback (/setinlinefeedback у @BotFather).
+func handleChosenInlineResult(bot *tgbotapi.BotAPI, result *tgbotapi.ChosenInlineResult, serviceURL string) {
+	command, ok := inlineResultCommands[result.ResultID]
+	if !ok || result.InlineMessageID == "" {
+		log.Printf("Пропускаем inline-результат %q без inline-сообщения", result.ResultID)
+		return
+	}
+
+	msg := newIncomingMessage(result.From, nil)
+	msg.InlineMessageID = result.InlineMessageID
+	msg.Text = &command
+
+	response, status := sendToBackend(serviceURL, msg)
+
+	// у автора приглашения может не быть личного чата с ботом, поэтому ошибку видно в самом приглашении
+	if errMsg, ok := response.(OutgoingMessage); ok && status != http.StatusOK {
+		errMsg.InlineMessageID = result.InlineMessageID
+		errMsg.Buttons = nil
+		sendSingleMessage(bot, 0, errMsg)
+		return
+	}
+
+	sendResponse(bot, 0, response)
+}
+
+// deliverySecretHeader - заголовок с общим секретом, который бэкенд передаёт вместе с уведомлением.
+const deliverySecretHeader = "X-Delivery-Secret"
+
+// serveDeliveries принимает push-уведомления от диспетчера outbox бэкенда.
+// Ответ не 2xx означает, что уведомление будет отправлено повторно. На том же адресе
+// /debug/vars отдаёт метрики обработки обновлений. Если задан secret, оба пути доступны
+// только с ним: иначе кто угодно мог бы рассылать сообщения от имени бота.
+func serveDeliveries(bot *tgbotapi.BotAPI, addr, secret string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", requireSecret(secret, expvar.Handler()))
+	mux.Handle("/deliver", requireSecret(secret, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
 		}
+
+		var msg OutgoingMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, "неверный формат уведомления", http.StatusBadRequest)
+			return
+		}
+
+		chatID := messageChatID(msg)
+		if chatID == 0 {
+			http.Error(w, "неизвестный получатель", http.StatusBadRequest)
+			return
+		}
+
+		log.Printf("Отправляем push-уведомление %s игроку %d", r.Header.Get("X-Notification-ID"), chatID)
+		if err := sendSingleMessage(bot, chatID, msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	log.Printf("Приём уведомлений на %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Ошибка запуска приёма уведомлений: %v", err)
+	}
+}
+
+// newIncomingMessage заполняет команду для бэкенда данными отправителя и чата; chat может быть nil.
+func newIncomingMessage(from *tgbotapi.User, chat *tgbotapi.Chat) IncomingMessage {
+	msg := IncomingMessage{UserName: getUserName(from)}
+	if from != nil {
+		msg.UserID = telegramUserID(from.ID)
+		msg.Username = from.UserName
+		msg.LanguageCode = from.LanguageCode
+	}
+	if chat != nil {
+		msg.ChatID = strconv.FormatInt(chat.ID, 10)
+		msg.ChatType = chat.Type
 	}
+	return msg
+}
 
-	callbackConfig := tgbotapi.NewCallback(callback.ID, "")
-	bot.Send(callbackConfig)
+// requireSecret пропускает только запросы с верным секретом в deliverySecretHeader.
+// Пустой secret допустим, только когда адрес приёма слушает localhost.
+func requireSecret(secret string, next http.Handler) http.Handler {
+	if secret == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(deliverySecretHeader)), []byte(secret)) != 1 {
+			log.Printf("Отклонён запрос к %s без верного секрета от %s", r.URL.Path, r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
-func sendToBackend(serviceURL, userID, userName, text string, action *string) (interface{}, int) {
-	msg := IncomingMessage{UserID: userID, UserName: userName}
-	if text != "" {
-		msg.Text = &text
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
 	}
-	if action != nil {
-		msg.Action = action
+	if host == "localhost" {
+		return true
 	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func sendToBackend(serviceURL string, msg IncomingMessage) (interface{}, int) {
+	userID := msg.UserID
 
 	jsonData, err := json.Marshal(msg)
 	if err != nil {
@@ -186,6 +427,15 @@ func sendToBackend(serviceURL, userID, userName, text string, action *string) (i
 
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("Ошибка от сервера: %s", body)
+
+		var errResp ErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Message != "" {
+			return OutgoingMessage{
+				UserID: userID,
+				Text:   "❌ " + errResp.Message,
+			}, resp.StatusCode
+		}
+
 		return OutgoingMessage{
 			UserID: userID,
 			Text:   "Сервер вернул ошибку: " + string(body),
@@ -213,54 +463,213 @@ func sendResponse(bot *tgbotapi.BotAPI, chatID int64, response interface{}) {
 	switch resp := response.(type) {
 	case OutgoingMessages:
 		for _, msg := range resp.Messages {
-			targetChatID := extractChatIDFromUserID(msg.UserID)
-			if targetChatID != 0 {
+			targetChatID := messageChatID(msg)
+			if targetChatID != 0 || msg.InlineMessageID != "" {
 				sendSingleMessage(bot, targetChatID, msg)
 			} else {
 				log.Printf("Не удалось извлечь chatID из userID: %s", msg.UserID)
 			}
 		}
 	case OutgoingMessage:
+		if resp.ChatID != "" || chatID == 0 {
+			chatID = messageChatID(resp)
+		}
 		sendSingleMessage(bot, chatID, resp)
 	default:
 		log.Printf("Неизвестный тип ответа: %T", response)
 	}
 }
 
-func extractChatIDFromUserID(userID string) int64 {
-	if strings.HasPrefix(userID, "chat_") {
-		chatIDStr := strings.TrimPrefix(userID, "chat_")
-		if chatID, err := strconv.ParseInt(chatIDStr, 10, 64); err == nil {
+// messageChatID возвращает чат для сообщения: указанный бэкендом или личный чат получателя.
+func messageChatID(msg OutgoingMessage) int64 {
+	if msg.ChatID != "" {
+		if chatID, err := strconv.ParseInt(msg.ChatID, 10, 64); err == nil {
 			return chatID
 		}
+		return 0
+	}
+	return extractChatIDFromUserID(msg.UserID)
+}
+
+// extractChatIDFromUserID возвращает личный чат пользователя: у Telegram он совпадает с id пользователя.
+// Префикс chat_ остался от старого формата идентификаторов и встречается в ещё не доставленных уведомлениях.
+func extractChatIDFromUserID(userID string) int64 {
+	for _, prefix := range []string{"user_", "chat_"} {
+		if strings.HasPrefix(userID, prefix) {
+			if chatID, err := strconv.ParseInt(strings.TrimPrefix(userID, prefix), 10, 64); err == nil {
+				return chatID
+			}
+		}
 	}
 	return 0
 }
 
-func sendSingleMessage(bot *tgbotapi.BotAPI, chatID int64, response OutgoingMessage) {
-	msg := tgbotapi.NewMessage(chatID, response.Text)
+// maxTrackedMessages ограничивает число запоминаемых сообщений с Ref. Забытое сообщение
+// просто будет отправлено заново.
+const maxTrackedMessages = 10000
 
-	if len(response.Buttons) > 0 {
-		var rows [][]tgbotapi.InlineKeyboardButton
-		var row []tgbotapi.InlineKeyboardButton
+type messageKey struct {
+	chatID int64
+	ref    string
+}
 
-		for i, btn := range response.Buttons {
-			button := tgbotapi.NewInlineKeyboardButtonData(btn.Text, btn.Action)
-			row = append(row, button)
+type trackedMessage struct {
+	messageID int
+	text      string
+}
 
-			if (i+1)%3 == 0 || i == len(response.Buttons)-1 {
-				rows = append(rows, row)
-				row = []tgbotapi.InlineKeyboardButton{}
-			}
+// trackedMessages - последние отправленные сообщения с Ref по чатам. Хранятся в памяти:
+// после перезапуска бота первое обновление придёт новым сообщением.
+var trackedMessages = struct {
+	sync.Mutex
+	byKey map[messageKey]trackedMessage
+}{byKey: map[messageKey]trackedMessage{}}
+
+// chatLocks упорядочивают обновления сообщений с Ref в одном чате: ответ на команду и push-уведомление
+// с той же доской могут прийти одновременно, и без блокировки оба отправили бы новое сообщение.
+var chatLocks [64]sync.Mutex
+
+func chatLock(chatID int64) *sync.Mutex {
+	i := chatID % int64(len(chatLocks))
+	if i < 0 {
+		i = -i
+	}
+	return &chatLocks[i]
+}
+
+func sendSingleMessage(bot *tgbotapi.BotAPI, chatID int64, response OutgoingMessage) error {
+	keyboard := buildKeyboard(response.Buttons, response.Layout)
+
+	if response.InlineMessageID != "" {
+		return editInlineMessage(bot, response.InlineMessageID, response.Text, keyboard)
+	}
+
+	if response.Ref != "" {
+		lock := chatLock(chatID)
+		lock.Lock()
+		defer lock.Unlock()
+
+		key := messageKey{chatID: chatID, ref: response.Ref}
+		if edited, err := editMessage(bot, key, response.Text, keyboard); edited {
+			return nil
+		} else if err != nil {
+			log.Printf("Не удалось обновить сообщение %s, отправляем новое: %v", response.Ref, err)
 		}
+	}
 
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
-		msg.ReplyMarkup = keyboard
+	msg := tgbotapi.NewMessage(chatID, response.Text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
 	}
 
-	if _, err := bot.Send(msg); err != nil {
+	sent, err := bot.Send(msg)
+	if err != nil {
 		log.Printf("Ошибка при отправке сообщения: %v", err)
+		return err
 	}
+
+	if response.Ref != "" {
+		rememberMessage(messageKey{chatID: chatID, ref: response.Ref}, trackedMessage{messageID: sent.MessageID, text: response.Text})
+	}
+	return nil
+}
+
+// editMessage обновляет ранее отправленное сообщение: если текст не изменился, меняются только кнопки.
+// Возвращает false, если сообщения нет или Telegram не дал его изменить (например, оно слишком старое).
+func editMessage(bot *tgbotapi.BotAPI, key messageKey, text string, keyboard *tgbotapi.InlineKeyboardMarkup) (bool, error) {
+	trackedMessages.Lock()
+	previous, ok := trackedMessages.byKey[key]
+	trackedMessages.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	var edit tgbotapi.Chattable
+	if previous.text == text {
+		markup := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+		if keyboard != nil {
+			markup = *keyboard
+		}
+		edit = tgbotapi.NewEditMessageReplyMarkup(key.chatID, previous.messageID, markup)
+	} else {
+		editText := tgbotapi.NewEditMessageText(key.chatID, previous.messageID, text)
+		editText.ReplyMarkup = keyboard
+		edit = editText
+	}
+
+	if _, err := bot.Request(edit); err != nil {
+		if strings.Contains(err.Error(), "message is not modified") {
+			return true, nil
+		}
+		forgetMessage(key)
+		return false, err
+	}
+
+	rememberMessage(key, trackedMessage{messageID: previous.messageID, text: text})
+	return true, nil
+}
+
+// editInlineMessage заменяет текст и кнопки inline-сообщения. Отправить новое сообщение вместо него
+// нельзя: у бота нет доступа к чату, куда оно отправлено.
+func editInlineMessage(bot *tgbotapi.BotAPI, inlineMessageID, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.EditMessageTextConfig{
+		BaseEdit: tgbotapi.BaseEdit{InlineMessageID: inlineMessageID, ReplyMarkup: keyboard},
+		Text:     text,
+	}
+
+	if _, err := bot.Request(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Ошибка при обновлении inline-сообщения: %v", err)
+		return err
+	}
+	return nil
+}
+
+func rememberMessage(key messageKey, msg trackedMessage) {
+	trackedMessages.Lock()
+	defer trackedMessages.Unlock()
+
+	if _, ok := trackedMessages.byKey[key]; !ok && len(trackedMessages.byKey) >= maxTrackedMessages {
+		for old := range trackedMessages.byKey {
+			delete(trackedMessages.byKey, old)
+			break
+		}
+	}
+	trackedMessages.byKey[key] = msg
+}
+
+func forgetMessage(key messageKey) {
+	trackedMessages.Lock()
+	defer trackedMessages.Unlock()
+	delete(trackedMessages.byKey, key)
+}
+
+// buildKeyboard раскладывает кнопки по рядам согласно layout; кнопки сверх layout идут по одной в ряд.
+func buildKeyboard(buttons []Button, layout []int) *tgbotapi.InlineKeyboardMarkup {
+	if len(buttons) == 0 {
+		return nil
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; len(buttons) > 0; i++ {
+		size := 1
+		if i < len(layout) && layout[i] > 0 {
+			size = min(layout[i], len(buttons))
+		}
+
+		var row []tgbotapi.InlineKeyboardButton
+		for _, btn := range buttons[:size] {
+			action := btn.Action
+			if action == "" {
+				action = noopAction
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(btn.Text, action))
+		}
+		rows = append(rows, row)
+		buttons = buttons[size:]
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
 }
 
 func getUserName(user *tgbotapi.User) string {
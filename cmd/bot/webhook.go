@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// secretTokenHeader - заголовок, в котором Telegram передаёт секретный токен, указанный при регистрации webhook.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxUpdateSize - предельный размер тела запроса к webhook. Обновления Telegram намного меньше,
+// а тело большего размера не читается целиком в память.
+const maxUpdateSize = 1 << 20
+
+// secretTokenPattern - допустимый формат секретного токена по документации Bot API.
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// webhookConfig - настройки приёма обновлений через webhook. URL - публичный адрес, который
+// регистрируется в Telegram (обычно за обратным прокси с HTTPS), Addr - адрес, на котором слушает бот.
+type webhookConfig struct {
+	URL    string
+	Addr   string
+	Secret string
+}
+
+func webhookConfigFromEnv() (webhookConfig, error) {
+	cfg := webhookConfig{
+		URL:    os.Getenv("WEBHOOK_URL"),
+		Addr:   os.Getenv("WEBHOOK_ADDR"),
+		Secret: os.Getenv("WEBHOOK_SECRET"),
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":8443"
+	}
+
+	if cfg.URL == "" {
+		return cfg, fmt.Errorf("не указан публичный адрес webhook в WEBHOOK_URL")
+	}
+	if !secretTokenPattern.MatchString(cfg.Secret) {
+		return cfg, fmt.Errorf("WEBHOOK_SECRET должен состоять из 1-256 символов A-Z, a-z, 0-9, _ и -")
+	}
+	return cfg, nil
+}
+
+// listenForWebhook начинает принимать обновления по HTTP и регистрирует webhook в Telegram.
+// Сервер запускается до регистрации, чтобы не потерять первые обновления.
+func listenForWebhook(bot *tgbotapi.BotAPI, cfg webhookConfig) (tgbotapi.UpdatesChannel, error) {
+	link, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("неверный WEBHOOK_URL: %w", err)
+	}
+	path := link.Path
+	if path == "" {
+		path = "/"
+	}
+
+	updates := make(chan tgbotapi.Update, bot.Buffer)
+	mux := http.NewServeMux()
+	mux.Handle(path, webhookHandler(cfg.Secret, updates))
+
+	listener, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запуска приёма обновлений: %w", err)
+	}
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Fatalf("Ошибка приёма обновлений: %v", err)
+		}
+	}()
+
+	params := tgbotapi.Params{"url": link.String(), "secret_token": cfg.Secret}
+	if _, err := bot.MakeRequest("setWebhook", params); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("ошибка регистрации webhook: %w", err)
+	}
+
+	log.Printf("Webhook зарегистрирован, приём обновлений на %s%s", cfg.Addr, path)
+	return updates, nil
+}
+
+// webhookHandler передаёт обновления от Telegram в updates. Адрес webhook может узнать кто угодно,
+// поэтому запросы без секретного токена, известного только Telegram, отклоняются.
+// Ответ не 2xx означает, что Telegram пришлёт обновление повторно.
+func webhookHandler(secret string, updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secret)) != 1 {
+			log.Printf("Отклонён запрос к webhook без верного секретного токена от %s", r.RemoteAddr)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			http.Error(w, "неверный формат обновления", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeBotAPI - сервер Bot API, который запоминает вызванные методы и их параметры.
+type fakeBotAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string][]map[string]string
+}
+
+func newFakeBotAPI(t *testing.T) *fakeBotAPI {
+	api := &fakeBotAPI{requests: map[string][]map[string]string{}}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := path.Base(r.URL.Path)
+		r.ParseForm()
+		params := map[string]string{}
+		for key := range r.PostForm {
+			params[key] = r.PostForm.Get(key)
+		}
+
+		api.mu.Lock()
+		api.requests[method] = append(api.requests[method], params)
+		api.mu.Unlock()
+
+		switch method {
+		case "getMe":
+			io.WriteString(w, `{"ok":true,"result":{"id":1,"is_bot":true,"username":"tttbot"}}`)
+		case "sendMessage":
+			io.WriteString(w, `{"ok":true,"result":{"message_id":1,"chat":{"id":42,"type":"private"}}}`)
+		default:
+			io.WriteString(w, `{"ok":true,"result":true}`)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *fakeBotAPI) calls(method string) []map[string]string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.requests[method]
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func postUpdate(t *testing.T, url, secret, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "" {
+		req.Header.Set(secretTokenHeader, secret)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebhook(t *testing.T) {
+	api := newFakeBotAPI(t)
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("T", api.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received []IncomingMessage
+	var mu sync.Mutex
+	service := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg IncomingMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		received = append(received, msg)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(OutgoingMessage{UserID: msg.UserID, Text: "ответ"})
+	}))
+	defer service.Close()
+
+	cfg := webhookConfig{URL: "https://example.com/tg/hook", Addr: freeAddr(t), Secret: "s3cret"}
+	updates, err := listenForWebhook(bot, cfg)
+	if err != nil {
+		t.Fatalf("listenForWebhook: %v", err)
+	}
+
+	setWebhook := api.calls("setWebhook")
+	if len(setWebhook) != 1 {
+		t.Fatalf("setWebhook вызван %d раз", len(setWebhook))
+	}
+	if got := setWebhook[0]["secret_token"]; got != cfg.Secret {
+		t.Errorf("secret_token = %q, want %q", got, cfg.Secret)
+	}
+	if got := setWebhook[0]["url"]; got != cfg.URL {
+		t.Errorf("url = %q, want %q", got, cfg.URL)
+	}
+
+	hookURL := "http://" + cfg.Addr + "/tg/hook"
+	update := `{"update_id":1,"message":{"message_id":5,"from":{"id":42,"first_name":"Ann"},"chat":{"id":42,"type":"private"},"text":"/start"}}`
+
+	for _, secret := range []string{"", "wrong"} {
+		if status := postUpdate(t, hookURL, secret, update); status != http.StatusUnauthorized {
+			t.Errorf("токен %q: статус %d, want %d", secret, status, http.StatusUnauthorized)
+		}
+	}
+	if status := postUpdate(t, hookURL, cfg.Secret, strings.Repeat(" ", maxUpdateSize+1)+"{}"); status != http.StatusBadRequest {
+		t.Errorf("слишком большое тело: статус %d, want %d", status, http.StatusBadRequest)
+	}
+
+	if status := postUpdate(t, hookURL, cfg.Secret, update); status != http.StatusOK {
+		t.Fatalf("верное обновление: статус %d, want %d", status, http.StatusOK)
+	}
+	select {
+	case u := <-updates:
+		handleUpdate(bot, u, service.URL)
+	case <-time.After(5 * time.Second):
+		t.Fatal("обновление не попало в канал")
+	}
+	select {
+	case u := <-updates:
+		t.Fatalf("отклонённое обновление попало в канал: %d", u.UpdateID)
+	default:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].UserID != "user_42" || received[0].Text == nil || *received[0].Text != "/start" {
+		t.Errorf("бэкенд получил %+v", received)
+	}
+	sent := api.calls("sendMessage")
+	if len(sent) != 1 || sent[0]["chat_id"] != "42" || sent[0]["text"] != "ответ" {
+		t.Errorf("sendMessage = %v", sent)
+	}
+}
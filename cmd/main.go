@@ -11,7 +11,10 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/tictactoe/internal/app"
 	"github.com/tictactoe/internal/config"
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/infrastructure/notifier"
 	"github.com/tictactoe/internal/infrastructure/postgres"
+	"github.com/tictactoe/internal/infrastructure/sqlite"
 	httpHandler "github.com/tictactoe/internal/interfaces/http"
 )
 
@@ -24,11 +27,98 @@ func init() {
 func main() {
 	cfg := config.New()
 
-	db := cfg.ConnectDB()
-	defer db.Close(context.Background())
+	var gameRepo domain.GameRepository
+	var archiveRepo domain.GameArchiveRepository
+	var notificationRepo domain.NotificationRepository
+	var userRepo domain.UserRepository
+	var ratingRepo domain.RatingRepository
+	var leaderboardRepo domain.LeaderboardRepository
+	var matchmakingRepo domain.MatchmakingRepository
+	var challengeRepo domain.ChallengeRepository
+	var friendRepo domain.FriendRepository
+	var blockRepo domain.BlockRepository
+	var achievementRepo domain.AchievementRepository
+	var tournamentRepo domain.TournamentRepository
+	var seasonRepo domain.SeasonRepository
 
-	gameRepo := postgres.NewGameRepository(db)
-	gameService := app.NewGameService(gameRepo)
+	switch cfg.StorageDriver {
+	case config.StorageSQLite:
+		db := cfg.ConnectSQLite()
+		defer db.Close()
+
+		if err := sqlite.Migrate(db); err != nil {
+			log.Fatalf("Ошибка применения миграций SQLite: %v", err)
+		}
+		gameRepo = sqlite.NewGameRepository(db)
+		archiveRepo = sqlite.NewGameArchiveRepository(db)
+		notificationRepo = sqlite.NewNotificationRepository(db)
+		userRepo = sqlite.NewUserRepository(db)
+		ratingRepo = sqlite.NewRatingRepository(db)
+		leaderboardRepo = sqlite.NewLeaderboardRepository(db)
+		matchmakingRepo = sqlite.NewMatchmakingRepository(db)
+		challengeRepo = sqlite.NewChallengeRepository(db)
+		friendRepo = sqlite.NewFriendRepository(db)
+		blockRepo = sqlite.NewBlockRepository(db)
+		achievementRepo = sqlite.NewAchievementRepository(db)
+		tournamentRepo = sqlite.NewTournamentRepository(db)
+		seasonRepo = sqlite.NewSeasonRepository(db)
+
+	case config.StoragePostgres, config.StorageEventStore:
+		db := cfg.ConnectDB()
+		defer db.Close()
+
+		if cfg.StorageDriver == config.StorageEventStore {
+			gameRepo = postgres.NewEventSourcedGameRepository(db, cfg.EventSnapshotInterval)
+		} else {
+			gameRepo = postgres.NewGameRepository(db)
+		}
+		archiveRepo = postgres.NewGameArchiveRepository(db)
+		notificationRepo = postgres.NewNotificationRepository(db)
+		userRepo = postgres.NewUserRepository(db)
+		ratingRepo = postgres.NewRatingRepository(db)
+		leaderboardRepo = postgres.NewLeaderboardRepository(db)
+		matchmakingRepo = postgres.NewMatchmakingRepository(db)
+		challengeRepo = postgres.NewChallengeRepository(db)
+		friendRepo = postgres.NewFriendRepository(db)
+		blockRepo = postgres.NewBlockRepository(db)
+		achievementRepo = postgres.NewAchievementRepository(db)
+		tournamentRepo = postgres.NewTournamentRepository(db)
+		seasonRepo = postgres.NewSeasonRepository(db)
+
+	default:
+		log.Fatalf("Неизвестное хранилище STORAGE_DRIVER: %s", cfg.StorageDriver)
+	}
+
+	gameService := app.NewGameService(gameRepo, userRepo, ratingRepo, leaderboardRepo, blockRepo, achievementRepo)
+	userService := app.NewUserService(userRepo)
+	statsService := app.NewStatsService(gameRepo, userRepo, leaderboardRepo, achievementRepo, seasonRepo)
+	adminService := app.NewAdminService(archiveRepo, cfg.AdminIDs)
+	matchmakingService := app.NewMatchmakingService(matchmakingRepo, gameRepo, userRepo, gameService, cfg.MatchmakingInterval)
+	challengeService := app.NewChallengeService(challengeRepo, userRepo, blockRepo, gameService, cfg.ChallengeTTL)
+	friendService := app.NewFriendService(friendRepo, gameRepo, userRepo)
+	blockService := app.NewBlockService(blockRepo, userRepo)
+	tournamentService := app.NewTournamentService(tournamentRepo, gameRepo, userRepo, gameService, cfg.TournamentInterval, cfg.TournamentRoundTime)
+	seasonService := app.NewSeasonService(seasonRepo, leaderboardRepo, cfg.SeasonLength, cfg.SeasonInterval)
+	gameService.OnGameFinished(tournamentService.HandleGameFinished)
+
+	go matchmakingService.Run(context.Background())
+	go tournamentService.Run(context.Background())
+	go seasonService.Run(context.Background())
+
+	if cfg.ArchiveAfter > 0 {
+		archiver := app.NewGameArchiver(archiveRepo, cfg.ArchiveAfter, cfg.ArchiveInterval, cfg.ArchiveBatchSize)
+		go archiver.Run(context.Background())
+		log.Printf("Архивация завершённых игр старше %s включена", cfg.ArchiveAfter)
+	}
+
+	if cfg.NotifyURL != "" {
+		sender := notifier.NewHTTPSender(cfg.NotifyURL, cfg.NotifySecret)
+		dispatcher := app.NewNotificationDispatcher(notificationRepo, sender, cfg.NotifyInterval, cfg.NotifyBatchSize, cfg.NotifyMaxAttempts)
+		go dispatcher.Run(context.Background())
+		log.Printf("Доставка уведомлений включена: %s", cfg.NotifyURL)
+	} else {
+		log.Printf("Предупреждение: NOTIFY_URL не задан, уведомления копятся в outbox без доставки")
+	}
 
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
@@ -36,10 +126,10 @@ func main() {
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 
-	commandHandler := httpHandler.NewCommandHandler(gameService)
+	commandHandler := httpHandler.NewCommandHandler(gameService, userService, statsService, adminService, matchmakingService, challengeService, friendService, blockService, tournamentService, seasonService)
 	commandHandler.RegisterRoutes(r)
 
-	log.Printf("Сервер запущен на порту %s, окружение: %s", cfg.Port, cfg.Environment)
+	log.Printf("Сервер запущен на порту %s, окружение: %s, хранилище: %s", cfg.Port, cfg.Environment, cfg.StorageDriver)
 	if err := http.ListenAndServe(":"+cfg.Port, r); err != nil {
 		log.Fatalf("Ошибка запуска сервера: %v", err)
 	}
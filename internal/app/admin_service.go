@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+type AdminService struct {
+	archive  domain.GameArchiveRepository
+	adminIDs map[string]bool
+}
+
+func NewAdminService(archive domain.GameArchiveRepository, adminIDs []string) *AdminService {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[domain.NormalizeUserID(id)] = true
+	}
+	return &AdminService{archive: archive, adminIDs: admins}
+}
+
+func (s *AdminService) IsAdmin(userID string) bool {
+	return s.adminIDs[userID]
+}
+
+func (s *AdminService) RestoreGame(userID, gameID string) (*dto.OutgoingMessage, error) {
+	if !s.IsAdmin(userID) {
+		return nil, domain.ErrForbidden
+	}
+
+	game, err := s.archive.RestoreGame(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка восстановления игры из архива: %w", err)
+	}
+
+	result := "🤝 Ничья"
+	if winner := game.Winner(); winner != nil {
+		result = fmt.Sprintf("🏆 Победил %s (%s)", winner.Name, winner.Symbol)
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("♻️ Игра %s восстановлена из архива\n%s vs %s, %s\n\n%s\n%s",
+			game.ID,
+			game.Players[0].Name, game.Players[1].Name,
+			game.CreatedAt.Format("02.01.2006 15:04"),
+			renderBoard(game.Board),
+			result,
+		),
+		nil,
+	), nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+// GameArchiver периодически переносит завершённые игры старше retention в архив.
+type GameArchiver struct {
+	archive   domain.GameArchiveRepository
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+}
+
+func NewGameArchiver(archive domain.GameArchiveRepository, retention, interval time.Duration, batchSize int) *GameArchiver {
+	return &GameArchiver{
+		archive:   archive,
+		retention: retention,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+func (a *GameArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		archived, err := a.ArchiveOnce()
+		if err != nil {
+			log.Printf("Ошибка архивации игр: %v", err)
+		} else if archived > 0 {
+			log.Printf("Перенесено в архив игр: %d", archived)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *GameArchiver) ArchiveOnce() (int, error) {
+	before := time.Now().Add(-a.retention)
+	total := 0
+
+	for {
+		archived, err := a.archive.ArchiveFinishedGames(before, a.batchSize)
+		total += archived
+		if err != nil {
+			return total, err
+		}
+		if archived == 0 || archived < a.batchSize {
+			return total, nil
+		}
+	}
+}
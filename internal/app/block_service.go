@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+type BlockService struct {
+	blocks domain.BlockRepository
+	users  domain.UserRepository
+}
+
+func NewBlockService(blocks domain.BlockRepository, users domain.UserRepository) *BlockService {
+	return &BlockService{blocks: blocks, users: users}
+}
+
+// Block запрещает пользователю target присоединяться к играм userID, вызывать его и попадать с ним в пару.
+func (s *BlockService) Block(userID, target string) (*dto.OutgoingMessage, error) {
+	user, err := findUser(s.users, target)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+	}
+	if user.ID == userID {
+		return nil, domain.ErrBlockSelf
+	}
+
+	if err := s.blocks.Block(userID, user.ID); err != nil {
+		return nil, fmt.Errorf("ошибка блокировки пользователя: %w", err)
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("🚫 %s заблокирован(а). Этот игрок больше не сможет присоединиться к вашим играм или вызвать вас.", profileName(user)),
+		[]dto.Button{{Text: "↩️ Разблокировать", Action: "/unblock " + user.ID}},
+	), nil
+}
+
+func (s *BlockService) Unblock(userID, target string) (*dto.OutgoingMessage, error) {
+	user, err := findUser(s.users, target)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+	}
+
+	removed, err := s.blocks.Unblock(userID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разблокировки пользователя: %w", err)
+	}
+
+	text := fmt.Sprintf("%s не был(а) заблокирован(а).", profileName(user))
+	if removed {
+		text = fmt.Sprintf("✅ %s разблокирован(а).", profileName(user))
+	}
+
+	return dto.NewOutgoingMessage(userID, text, []dto.Button{{Text: "🚫 Заблокированные", Action: "/blocked"}}), nil
+}
+
+func (s *BlockService) ShowBlocked(userID string) (*dto.OutgoingMessage, error) {
+	ids, err := s.blocks.ListBlocked(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка блокировок: %w", err)
+	}
+	if len(ids) == 0 {
+		return dto.NewOutgoingMessage(userID, "🚫 Вы никого не блокировали.", nil), nil
+	}
+
+	profiles, err := s.users.GetByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения профилей: %w", err)
+	}
+
+	lines := []string{"🚫 Заблокированные игроки:", ""}
+	var buttons []dto.Button
+	for _, id := range ids {
+		name := getUserDisplayName(id)
+		if profile, ok := profiles[id]; ok {
+			name = profileName(profile)
+		}
+
+		lines = append(lines, "• "+name)
+		buttons = append(buttons, dto.Button{Text: "↩️ " + name, Action: "/unblock " + id})
+	}
+
+	return dto.NewOutgoingMessage(userID, strings.Join(lines, "\n"), buttons), nil
+}
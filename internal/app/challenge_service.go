@@ -0,0 +1,170 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+type ChallengeService struct {
+	challenges  domain.ChallengeRepository
+	users       domain.UserRepository
+	blocks      domain.BlockRepository
+	gameService *GameService
+	ttl         time.Duration
+}
+
+func NewChallengeService(
+	challenges domain.ChallengeRepository,
+	users domain.UserRepository,
+	blocks domain.BlockRepository,
+	gameService *GameService,
+	ttl time.Duration,
+) *ChallengeService {
+	return &ChallengeService{
+		challenges:  challenges,
+		users:       users,
+		blocks:      blocks,
+		gameService: gameService,
+		ttl:         ttl,
+	}
+}
+
+// Challenge отправляет вызов игроку; target - @username или идентификатор пользователя.
+func (s *ChallengeService) Challenge(userID, userName, target string) (*dto.OutgoingMessage, error) {
+	opponent, err := findUser(s.users, target)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+	}
+
+	challenge, err := domain.NewChallenge(userID, userName, opponent.ID, profileName(opponent), s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBlocked(userID, opponent.ID); err != nil {
+		return nil, err
+	}
+	challenge.ID = uuid.New().String()
+
+	if challenge.ChallengerName == "" {
+		challenge.ChallengerName = getUserDisplayName(userID)
+	}
+
+	notification, err := newNotification(dto.NewOutgoingMessage(
+		opponent.ID,
+		fmt.Sprintf("⚔️ %s вызывает вас на игру!\nВызов действует %s.", challenge.ChallengerName, formatTTL(s.ttl)),
+		[]dto.Button{
+			{Text: "✅ Принять", Action: "/accept " + challenge.ID},
+			{Text: "❌ Отклонить", Action: "/decline " + challenge.ID},
+		},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.challenges.Create(challenge, notification); err != nil {
+		return nil, fmt.Errorf("ошибка создания вызова: %w", err)
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("⚔️ Вызов отправлен игроку %s. Ждём ответа %s.", challenge.TargetName, formatTTL(s.ttl)),
+		[]dto.Button{{Text: "🎮 Моя игра", Action: "/mygame"}},
+	), nil
+}
+
+func (s *ChallengeService) Accept(userID, userName, challengeID string) (*dto.OutgoingMessage, error) {
+	challenge, err := s.challenges.GetByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBlocked(userID, challenge.ChallengerID); err != nil {
+		return nil, err
+	}
+
+	gameID := uuid.New().String()
+	if err := challenge.Accept(userID, gameID, time.Now()); err != nil {
+		s.saveExpired(challenge, err)
+		return nil, err
+	}
+
+	// вызов закрывается в одной транзакции с созданием игры: если второй ответ на него успел раньше,
+	// игра не создаётся и возвращается ErrChallengeClosed
+	game, err := s.gameService.startGame(
+		gameID,
+		domain.Player{ID: challenge.ChallengerID, Name: challenge.ChallengerName},
+		domain.Player{ID: userID, Name: userName},
+		false,
+		userID,
+		domain.GameOrigin{Challenge: challenge},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.gameService.getGameMessage(game, userID), nil
+}
+
+func (s *ChallengeService) Decline(userID, challengeID string) (*dto.OutgoingMessage, error) {
+	challenge, err := s.challenges.GetByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := challenge.Decline(userID, time.Now()); err != nil {
+		s.saveExpired(challenge, err)
+		return nil, err
+	}
+
+	notification, err := newNotification(dto.NewOutgoingMessage(
+		challenge.ChallengerID,
+		fmt.Sprintf("❌ %s отклонил(а) ваш вызов.", challenge.TargetName),
+		[]dto.Button{
+			{Text: "⚡ Быстрая игра", Action: "/play"},
+			{Text: "📋 Список игр", Action: "/list"},
+		},
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.challenges.Resolve(challenge, notification); err != nil {
+		return nil, err
+	}
+
+	return dto.NewOutgoingMessage(userID, "Вызов отклонён.", nil), nil
+}
+
+func (s *ChallengeService) checkBlocked(userID, otherID string) error {
+	blocked, err := s.blocks.IsBlocked(userID, otherID)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки блокировки: %w", err)
+	}
+	if blocked {
+		return domain.ErrUserBlocked
+	}
+	return nil
+}
+
+// saveExpired запоминает истечение вызова, обнаруженное при попытке ответа на него.
+func (s *ChallengeService) saveExpired(challenge *domain.Challenge, err error) {
+	if !errors.Is(err, domain.ErrChallengeExpired) {
+		return
+	}
+	if err := s.challenges.Resolve(challenge); err != nil && !errors.Is(err, domain.ErrChallengeClosed) {
+		log.Printf("Не удалось отметить истёкший вызов %s: %v", challenge.ID, err)
+	}
+}
+
+func formatTTL(ttl time.Duration) string {
+	if ttl < time.Hour {
+		return fmt.Sprintf("%d мин", int(ttl.Minutes()))
+	}
+	return fmt.Sprintf("%d ч", int(ttl.Hours()))
+}
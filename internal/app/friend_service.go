@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+const recentOpponentsLimit = 5
+
+type FriendService struct {
+	friends domain.FriendRepository
+	games   domain.GameRepository
+	users   domain.UserRepository
+}
+
+func NewFriendService(friends domain.FriendRepository, games domain.GameRepository, users domain.UserRepository) *FriendService {
+	return &FriendService{friends: friends, games: games, users: users}
+}
+
+func (s *FriendService) AddFriend(userID, target string) (*dto.OutgoingMessage, error) {
+	friend, err := findUser(s.users, target)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+	}
+	if friend.ID == userID {
+		return nil, domain.ErrFriendSelf
+	}
+
+	if err := s.friends.Add(userID, friend.ID); err != nil {
+		return nil, fmt.Errorf("ошибка добавления в друзья: %w", err)
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("🤝 %s теперь в списке ваших друзей.", profileName(friend)),
+		[]dto.Button{
+			{Text: "👥 Друзья", Action: "/friends"},
+			{Text: "⚔️ Вызвать на игру", Action: "/challenge " + friend.ID},
+		},
+	), nil
+}
+
+func (s *FriendService) RemoveFriend(userID, target string) (*dto.OutgoingMessage, error) {
+	friend, err := findUser(s.users, target)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+	}
+
+	removed, err := s.friends.Remove(userID, friend.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка удаления из друзей: %w", err)
+	}
+
+	text := fmt.Sprintf("%s нет в списке ваших друзей.", profileName(friend))
+	if removed {
+		text = fmt.Sprintf("%s удалён(а) из друзей.", profileName(friend))
+	}
+
+	return dto.NewOutgoingMessage(userID, text, []dto.Button{{Text: "👥 Друзья", Action: "/friends"}}), nil
+}
+
+func (s *FriendService) ShowFriends(userID string) (*dto.OutgoingMessage, error) {
+	friends, err := s.friends.List(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка друзей: %w", err)
+	}
+
+	if len(friends) == 0 {
+		return dto.NewOutgoingMessage(
+			userID,
+			"👥 Список друзей пуст.\nДобавьте друга командой /friends add @username или из списка недавних соперников.",
+			[]dto.Button{{Text: "🕑 Недавние соперники", Action: "/opponents"}},
+		), nil
+	}
+
+	lines := []string{"👥 Ваши друзья:", ""}
+	var buttons []dto.Button
+	for _, f := range friends {
+		name := f.DisplayName
+		if name == "" {
+			name = getUserDisplayName(f.UserID)
+		}
+
+		status := "⚪ свободен(на)"
+		switch f.GameStatus {
+		case domain.GameStatusActive:
+			status = "🎮 в игре"
+		case domain.GameStatusWaiting:
+			status = "⏳ ждёт соперника"
+		}
+
+		lines = append(lines, fmt.Sprintf("• %s — %s", name, status))
+		buttons = append(buttons, dto.Button{Text: "⚔️ " + name, Action: "/challenge " + f.UserID})
+	}
+	buttons = append(buttons, dto.Button{Text: "🕑 Недавние соперники", Action: "/opponents"})
+
+	return dto.NewOutgoingMessage(userID, strings.Join(lines, "\n"), buttons), nil
+}
+
+// ShowRecentOpponents строит список последних соперников по завершённым играм, включая архивные.
+func (s *FriendService) ShowRecentOpponents(userID string) (*dto.OutgoingMessage, error) {
+	results, err := s.games.GetFinishedGamesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения завершённых игр: %w", err)
+	}
+
+	var opponents []string
+	seen := map[string]bool{}
+	for i := len(results) - 1; i >= 0 && len(opponents) < recentOpponentsLimit; i-- {
+		opponentID := results[i].PlayerXID
+		if opponentID == userID {
+			opponentID = results[i].PlayerOID
+		}
+		if opponentID == "" || seen[opponentID] {
+			continue
+		}
+		seen[opponentID] = true
+		opponents = append(opponents, opponentID)
+	}
+
+	if len(opponents) == 0 {
+		return dto.NewOutgoingMessage(
+			userID,
+			"🕑 Вы ещё не доиграли ни одной игры.",
+			[]dto.Button{{Text: "⚡ Быстрая игра", Action: "/play"}},
+		), nil
+	}
+
+	profiles, err := s.users.GetByIDs(opponents)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения профилей: %w", err)
+	}
+
+	lines := []string{"🕑 Недавние соперники:", ""}
+	var buttons []dto.Button
+	for _, id := range opponents {
+		name := getUserDisplayName(id)
+		if profile, ok := profiles[id]; ok {
+			name = profileName(profile)
+		}
+
+		lines = append(lines, "• "+name)
+		buttons = append(buttons,
+			dto.Button{Text: "⚔️ " + name, Action: "/challenge " + id},
+			dto.Button{Text: "➕ В друзья", Action: "/friends add " + id},
+			dto.Button{Text: "🚫 Блок", Action: "/block " + id},
+		)
+	}
+
+	return dto.NewOutgoingMessage(userID, strings.Join(lines, "\n"), buttons), nil
+}
@@ -1,61 +1,152 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tictactoe/internal/domain"
 	"github.com/tictactoe/internal/dto"
 )
 
+const lobbyPageSize = 6
+
+// lobbyRatingRange - насколько рейтинг создателя игры может отличаться от рейтинга пользователя
+// в списке игр с фильтром по близкому рейтингу.
+const lobbyRatingRange = 200
+
 type GameService struct {
-	repo domain.GameRepository
+	repo         domain.GameRepository
+	users        domain.UserRepository
+	ratings      domain.RatingRepository
+	leaderboard  domain.LeaderboardRepository
+	blocks       domain.BlockRepository
+	achievements domain.AchievementRepository
+
+	finishHandlers []func(game *domain.Game)
+}
+
+// gameOutcome - итоги только что завершённой игры, которые ещё не сохранены и поэтому передаются явно.
+type gameOutcome struct {
+	ratingChanges []domain.RatingChange
+	achievements  []domain.UserAchievement
 }
 
-func NewGameService(repo domain.GameRepository) *GameService {
-	return &GameService{repo: repo}
+func NewGameService(
+	repo domain.GameRepository,
+	users domain.UserRepository,
+	ratings domain.RatingRepository,
+	leaderboard domain.LeaderboardRepository,
+	blocks domain.BlockRepository,
+	achievements domain.AchievementRepository,
+) *GameService {
+	return &GameService{
+		repo:         repo,
+		users:        users,
+		ratings:      ratings,
+		leaderboard:  leaderboard,
+		blocks:       blocks,
+		achievements: achievements,
+	}
+}
+
+func (s *GameService) ChooseGameType(userID string) *dto.OutgoingMessage {
+	return dto.NewOutgoingMessage(
+		userID,
+		"Выберите тип игры:\n🏆 Рейтинговая - результат меняет рейтинг\n🎲 Обычная - игра без рейтинга",
+		[]dto.Button{
+			{Text: "🏆 Рейтинговая", Action: "/new rated"},
+			{Text: "🎲 Обычная", Action: "/new casual"},
+		},
+	)
 }
 
 func (s *GameService) CreateGame(req dto.CreateGameRequest) (*dto.OutgoingMessage, error) {
-	game := domain.NewGame(req.UserID, req.UserName)
+	game := domain.NewGame(req.UserID, req.UserName, req.Rated, req.ChatID, req.InlineMessageID)
 	game.ID = uuid.New().String()
 
 	if err := s.repo.Create(game); err != nil {
 		return nil, fmt.Errorf("ошибка создания игры: %w", err)
 	}
+	s.addChatMember(req.ChatID, req.UserID)
+
+	if game.IsPublic() {
+		return s.getGameMessage(game, req.UserID), nil
+	}
+
+	text := "Игра создана! Ожидаем второго игрока..."
+	if game.Rated {
+		text = "🏆 Рейтинговая игра создана! Ожидаем второго игрока..."
+	}
 
 	return dto.NewOutgoingMessage(
 		req.UserID,
-		"Игра создана! Ожидаем второго игрока...",
+		text,
 		[]dto.Button{{Text: "Список игр", Action: "/list"}},
 	), nil
 }
 
-func (s *GameService) ListGames(userID string) (*dto.OutgoingMessage, error) {
-	games, err := s.repo.GetAvailableGames()
+func (s *GameService) ListGames(req dto.ListGamesRequest) (*dto.OutgoingMessage, error) {
+	userID, page := req.UserID, req.Page
+	if page < 1 {
+		page = 1
+	}
+
+	filter := domain.GameFilter{
+		Limit:            lobbyPageSize,
+		Offset:           (page - 1) * lobbyPageSize,
+		NewestFirst:      req.NewestFirst,
+		ExcludeCreatorID: userID,
+		ViewerID:         userID,
+	}
+	if req.NearRating {
+		rating := domain.DefaultRating
+		if user, err := s.users.GetByID(userID); err == nil {
+			rating = user.Rating
+		}
+		filter.MinRating = max(rating-lobbyRatingRange, 1)
+		filter.MaxRating = rating + lobbyRatingRange
+	}
+
+	result, err := s.repo.GetAvailableGames(filter)
+	if err == nil && len(result.Games) == 0 && page > 1 {
+		// пока страница была открыта, к играм могли присоединиться: вместо пустой показывается последняя
+		page, result, err = s.lastLobbyPage(filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения списка игр: %w", err)
 	}
+	s.applyProfileNames(result.Games...)
 
-	if len(games) == 0 {
-		return dto.NewOutgoingMessage(
-			userID,
-			"📭 Нет доступных игр",
-			[]dto.Button{
-				{Text: "🆕 Создать игру", Action: "/new"},
-				{Text: "🎮 Моя игра", Action: "/mygame"},
-			},
-		), nil
+	if len(result.Games) == 0 {
+		buttons := []dto.Button{
+			{Text: "🆕 Создать игру", Action: "/new"},
+			{Text: "🎮 Моя игра", Action: "/mygame"},
+		}
+		text := "📭 Нет доступных игр"
+		if req.NearRating {
+			text = fmt.Sprintf("📭 Нет игр соперников с рейтингом %d-%d", filter.MinRating, filter.MaxRating)
+			buttons = append(buttons, dto.Button{Text: "🌐 Любой рейтинг", Action: listAction(1, req.NewestFirst, false)})
+		}
+		return dto.NewOutgoingMessage(userID, text, buttons), nil
 	}
 
+	offset := (page - 1) * lobbyPageSize
+
 	var buttons []dto.Button
-	for i, game := range games {
+	for i, game := range result.Games {
 		creatorName := game.Players[0].Name
 		if creatorName == "" {
 			creatorName = getUserDisplayName(game.Players[0].ID)
 		}
-		buttonText := fmt.Sprintf("🎮 Игра %d", i+1)
+		icon := "🎮"
+		if game.Rated {
+			icon = "🏆"
+		}
+		buttonText := fmt.Sprintf("%s Игра %d", icon, offset+i+1)
 		if creatorName != "" {
 			buttonText += fmt.Sprintf(" (от %s)", creatorName)
 		}
@@ -65,40 +156,123 @@ func (s *GameService) ListGames(userID string) (*dto.OutgoingMessage, error) {
 		})
 	}
 
-	return dto.NewOutgoingMessage(userID, fmt.Sprintf("🎯 Доступные игры (%d):", len(games)), buttons), nil
+	if page > 1 {
+		buttons = append(buttons, dto.Button{Text: "⬅️ Назад", Action: listAction(page-1, req.NewestFirst, req.NearRating)})
+	}
+	if offset+len(result.Games) < result.Total {
+		buttons = append(buttons, dto.Button{Text: "➡️ Далее", Action: listAction(page+1, req.NewestFirst, req.NearRating)})
+	}
+
+	// смена сортировки или фильтра возвращает на первую страницу
+	if req.NewestFirst {
+		buttons = append(buttons, dto.Button{Text: "🕰 Сначала старые", Action: listAction(1, false, req.NearRating)})
+	} else {
+		buttons = append(buttons, dto.Button{Text: "🆕 Сначала новые", Action: listAction(1, true, req.NearRating)})
+	}
+	if req.NearRating {
+		buttons = append(buttons, dto.Button{Text: "🌐 Любой рейтинг", Action: listAction(1, req.NewestFirst, false)})
+	} else {
+		buttons = append(buttons, dto.Button{Text: "🎯 Близкий рейтинг", Action: listAction(1, req.NewestFirst, true)})
+	}
+
+	pages := (result.Total + lobbyPageSize - 1) / lobbyPageSize
+	text := fmt.Sprintf("🎯 Доступные игры (%d):", result.Total)
+	if pages > 1 {
+		text = fmt.Sprintf("🎯 Доступные игры (%d), страница %d из %d:", result.Total, page, pages)
+	}
+	if req.NearRating {
+		text += fmt.Sprintf("\nРейтинг соперника: %d-%d", filter.MinRating, filter.MaxRating)
+	}
+
+	return dto.NewOutgoingMessage(userID, text, buttons), nil
+}
+
+// lastLobbyPage возвращает номер и содержимое последней страницы списка игр.
+func (s *GameService) lastLobbyPage(filter domain.GameFilter) (int, *domain.GamePage, error) {
+	filter.Offset = 0
+	first, err := s.repo.GetAvailableGames(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	last := (first.Total + lobbyPageSize - 1) / lobbyPageSize
+	if last <= 1 {
+		return 1, first, nil
+	}
+
+	filter.Offset = (last - 1) * lobbyPageSize
+	result, err := s.repo.GetAvailableGames(filter)
+	return last, result, err
+}
+
+// listAction строит команду страницы списка игр: /list <страница> [new] [near].
+func listAction(page int, newestFirst, nearRating bool) string {
+	action := fmt.Sprintf("/list %d", page)
+	if newestFirst {
+		action += " new"
+	}
+	if nearRating {
+		action += " near"
+	}
+	return action
 }
 
 func (s *GameService) JoinGame(req dto.JoinGameRequest) (*dto.OutgoingMessages, error) {
-	game, err := s.repo.GetByID(req.GameID)
+	var game *domain.Game
+	var err error
+	if req.GameID == "" && req.InlineMessageID != "" {
+		game, err = s.repo.GetByInlineMessageID(req.InlineMessageID)
+	} else {
+		game, err = s.repo.GetByID(req.GameID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения игры: %w", err)
 	}
+	s.applyProfileNames(game)
+
+	blocked, err := s.blocks.IsBlocked(req.UserID, game.Players[0].ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки блокировки: %w", err)
+	}
+	if blocked {
+		return nil, domain.ErrUserBlocked
+	}
 
 	if err := game.JoinGame(req.UserID, req.UserName); err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.Update(game); err != nil {
-		return nil, fmt.Errorf("ошибка обновления игры: %w", err)
+	notifications, err := s.opponentNotifications(game, req.UserID, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var messages []dto.OutgoingMessage
-
-	playerMessage := s.getGameMessage(game, req.UserID)
-	messages = append(messages, *playerMessage)
-
-	creatorMessage := s.getGameMessage(game, game.Players[0].ID)
-	messages = append(messages, *creatorMessage)
+	if err := s.repo.Join(game, notifications...); err != nil {
+		return nil, fmt.Errorf("ошибка обновления игры: %w", err)
+	}
+	s.addChatMember(req.ChatID, req.UserID)
 
-	return dto.NewOutgoingMessages(messages...), nil
+	return dto.NewOutgoingMessages(s.playerMessages(game, nil)...), nil
 }
 
 func (s *GameService) ShowHelp(userID string) *dto.OutgoingMessage {
 	helpText := `🎯 Добро пожаловать в Tic-Tac-Toe!
 
 ✨ Доступные команды:
-• /new - создать новую игру
+• /new - создать новую игру (рейтинговую или обычную)
 • /list - список доступных игр
+• /play - быстрая игра с соперником близкого рейтинга
+• /challenge @username - вызвать игрока на игру
+• /friends - друзья, /friends add @username - добавить друга
+• /opponents - недавние соперники
+• /block @username - заблокировать игрока, /blocked - список заблокированных
+• /mygame - текущая игра
+• /stats - ваша статистика, /stats @username - статистика другого игрока
+• /profile - рейтинг и награды, /profile @username - профиль другого игрока
+• /tournaments - турниры, /tournament new round 30 Название - создать турнир
+• /top - таблица лидеров, /top wins week - по победам за неделю
+• /season - текущий сезон и ваше место, /season 2 - итоги сезона
+• @имя бота в любом чате - пригласить собеседника сыграть прямо там
 
 🎲 Как играть:
 1. Создайте игру командой /new
@@ -112,7 +286,7 @@ A |_|_|_|
 B |_|_|_|
 C |_|_|_|
 
-💡 Нажимайте на кнопки с координатами (A1, B2, C3 и т.д.) для совершения хода.
+💡 Доска - это кнопки под сообщением игры: нажмите на пустую клетку, чтобы сделать ход.
 
 🚀 Удачи в игре!`
 
@@ -121,17 +295,110 @@ C |_|_|_|
 		helpText,
 		[]dto.Button{
 			{Text: "🆕 Создать игру", Action: "/new"},
+			{Text: "⚡ Быстрая игра", Action: "/play"},
 			{Text: "📋 Список игр", Action: "/list"},
 			{Text: "🎮 Моя игра", Action: "/mygame"},
+			{Text: "📊 Статистика", Action: "/stats"},
+			{Text: "🏆 Лидеры", Action: "/top"},
+			{Text: "👥 Друзья", Action: "/friends"},
 		},
 	)
 }
 
 func (s *GameService) getGameMessage(game *domain.Game, userID string) *dto.OutgoingMessage {
-	boardText := renderBoard(game.Board)
+	return s.gameMessage(game, userID, nil)
+}
+
+// gameMessage строит сообщение о состоянии игры для игрока. Сообщение ссылается на доску игры,
+// поэтому бот обновляет уже отправленную доску, а не присылает новую после каждого хода.
+func (s *GameService) gameMessage(game *domain.Game, userID string, outcome *gameOutcome) *dto.OutgoingMessage {
+	var msg *dto.OutgoingMessage
+	if game.IsPublic() {
+		msg = s.publicBoardMessage(game, userID, outcome)
+		msg.ChatID = game.ChatID
+		msg.InlineMessageID = game.InlineMessageID
+	} else {
+		msg = s.boardMessage(game, userID, outcome)
+	}
+	msg.Ref = boardRef(game.ID)
+	return msg
+}
+
+// publicBoardMessage строит общую доску игры группового чата или inline-сообщения: к ожидающей игре
+// может присоединиться любой, кто видит доску, а ходить нажатием на клетку - только тот, чья очередь.
+func (s *GameService) publicBoardMessage(game *domain.Game, userID string, outcome *gameOutcome) *dto.OutgoingMessage {
+	creator := playerTitle(game.Players[0])
 
+	switch game.Status {
+	case domain.GameStatusWaiting:
+		text := fmt.Sprintf("🎮 %s ищет соперника. Нажмите «Присоединиться», чтобы сыграть.", creator)
+		if game.Rated {
+			text = fmt.Sprintf("🏆 %s ищет соперника для рейтинговой игры. Нажмите «Присоединиться», чтобы сыграть.", creator)
+		}
+		return dto.NewBoardMessage(userID, text, boardButtons(game.ID, game.Board, false),
+			[]dto.Button{{Text: "✋ Присоединиться", Action: "/join " + game.ID}})
+
+	case domain.GameStatusFinished:
+		text := playersLine(game) + "\n\n🤝 Ничья!"
+		if winner := game.Winner(); winner != nil {
+			text = fmt.Sprintf("%s\n\n🏆 Победил %s!", playersLine(game), playerTitle(*winner))
+		}
+
+		if outcome == nil {
+			outcome = s.loadOutcome(game)
+		}
+		for _, p := range game.Players {
+			if extra := ratingChangeText(outcome.ratingChanges, p.ID) + achievementsText(outcome.achievements, p.ID); extra != "" {
+				text += "\n\n" + playerTitle(p) + ":" + extra
+			}
+		}
+
+		// в inline-сообщении нельзя выбрать тип игры, поэтому новая игра начинается там же с тем же типом
+		newGame := dto.Button{Text: "🆕 Новая игра", Action: "/new"}
+		if game.InlineMessageID != "" {
+			newGame = dto.Button{Text: "🔁 Сыграть ещё", Action: "/new casual"}
+			if game.Rated {
+				newGame.Action = "/new rated"
+			}
+		}
+
+		return dto.NewBoardMessage(userID, text, boardButtons(game.ID, game.Board, false), []dto.Button{newGame})
+	}
+
+	text := playersLine(game)
+	for _, p := range game.Players {
+		if p.IsActive {
+			text += "\n\n🎯 Ходит " + playerTitle(p)
+		}
+	}
+	return dto.NewBoardMessage(userID, text, boardButtons(game.ID, game.Board, true), nil)
+}
+
+func playersLine(game *domain.Game) string {
+	return playerTitle(game.Players[0]) + " vs " + playerTitle(game.Players[1])
+}
+
+func playerTitle(p domain.Player) string {
+	name := p.Name
+	if name == "" {
+		name = getUserDisplayName(p.ID)
+	}
+	if p.Symbol == "" {
+		return name
+	}
+	return cellText(p.Symbol) + " " + name
+}
+
+func boardRef(gameID string) string {
+	return "game:" + gameID
+}
+
+// boardMessage строит сообщение с доской в виде клавиатуры. Если итоги завершённой игры
+// не переданы в outcome, они загружаются из истории рейтинга и наград.
+func (s *GameService) boardMessage(game *domain.Game, userID string, outcome *gameOutcome) *dto.OutgoingMessage {
 	var isYourTurn bool
 	var yourSymbol string
+	var opponentID, opponentName string
 	var found bool
 
 	for _, p := range game.Players {
@@ -139,7 +406,12 @@ func (s *GameService) getGameMessage(game *domain.Game, userID string) *dto.Outg
 			isYourTurn = p.IsActive
 			yourSymbol = p.Symbol
 			found = true
-			break
+		} else if p.ID != "" {
+			opponentID = p.ID
+			opponentName = p.Name
+			if opponentName == "" {
+				opponentName = getUserDisplayName(p.ID)
+			}
 		}
 	}
 
@@ -162,33 +434,44 @@ func (s *GameService) getGameMessage(game *domain.Game, userID string) *dto.Outg
 			text = "🤝 Игра окончена. Ничья!"
 		}
 
-		return dto.NewOutgoingMessage(
+		if outcome == nil {
+			outcome = s.loadOutcome(game)
+		}
+		text += ratingChangeText(outcome.ratingChanges, userID)
+		text += achievementsText(outcome.achievements, userID)
+
+		return dto.NewBoardMessage(
 			userID,
-			fmt.Sprintf("%s\n\n%s", boardText, text),
+			text,
+			boardButtons(game.ID, game.Board, false),
 			[]dto.Button{
 				{Text: "🆕 Новая игра", Action: "/new"},
 				{Text: "📋 Список игр", Action: "/list"},
+				{Text: "🚫 Заблокировать соперника", Action: "/block " + opponentID},
 			},
 		)
 	} else if game.Status == domain.GameStatusWaiting {
-		return dto.NewOutgoingMessage(
+		return dto.NewBoardMessage(
 			userID,
-			fmt.Sprintf("%s\n\n⏳ Ожидаем второго игрока...", boardText),
+			"⏳ Ожидаем второго игрока...",
+			boardButtons(game.ID, game.Board, false),
 			[]dto.Button{
 				{Text: "📋 Список игр", Action: "/list"},
 				{Text: "🎮 Моя игра", Action: "/mygame"},
 			},
 		)
 	} else if isYourTurn {
-		return dto.NewOutgoingMessage(
+		return dto.NewBoardMessage(
 			userID,
-			fmt.Sprintf("%s\n\n🎯 Ваш ход! Вы играете за %s", boardText, yourSymbol),
-			generateMoveButtons(game.ID, game.Board),
+			fmt.Sprintf("🎯 Ваш ход! Вы играете за %s против %s", cellText(yourSymbol), opponentName),
+			boardButtons(game.ID, game.Board, true),
+			nil,
 		)
 	} else {
-		return dto.NewOutgoingMessage(
+		return dto.NewBoardMessage(
 			userID,
-			fmt.Sprintf("%s\n\n⏳ Ожидаем ход противника... Вы играете за %s", boardText, yourSymbol),
+			fmt.Sprintf("⏳ Ожидаем ход противника (%s)... Вы играете за %s", opponentName, cellText(yourSymbol)),
+			boardButtons(game.ID, game.Board, false),
 			[]dto.Button{
 				{Text: "🎮 Моя игра", Action: "/mygame"},
 			},
@@ -199,39 +482,38 @@ func (s *GameService) getGameMessage(game *domain.Game, userID string) *dto.Outg
 func (s *GameService) MakeMove(req dto.MakeMoveRequest) (*dto.OutgoingMessages, error) {
 	game, err := s.repo.GetByID(req.GameID)
 	if err != nil {
-		return nil, fmt.Errorf("игра не найдена: %w", err)
+		return nil, fmt.Errorf("ошибка получения игры: %w", err)
 	}
+	s.applyProfileNames(game)
 
 	coord, err := parseCoordinate(req.Position)
 	if err != nil {
-		return nil, fmt.Errorf("неверные координаты: %w", err)
+		return nil, err
 	}
 
 	if err := game.MakeMove(req.UserID, coord); err != nil {
 		return nil, err
 	}
 
-	if err := s.repo.Update(game); err != nil {
+	outcome, err := s.saveMove(game, req.UserID)
+	if err != nil {
 		return nil, fmt.Errorf("ошибка сохранения хода: %w", err)
 	}
-
-	var messages []dto.OutgoingMessage
-
-	for _, player := range game.Players {
-		if player.ID != "" {
-			playerMessage := s.getGameMessage(game, player.ID)
-			messages = append(messages, *playerMessage)
+	if game.Status == domain.GameStatusFinished {
+		for _, handler := range s.finishHandlers {
+			handler(game)
 		}
 	}
 
-	return dto.NewOutgoingMessages(messages...), nil
+	return dto.NewOutgoingMessages(s.playerMessages(game, outcome)...), nil
 }
 
 func (s *GameService) ShowGame(req dto.ShowGameRequest) (*dto.OutgoingMessage, error) {
 	game, err := s.repo.GetByID(req.GameID)
 	if err != nil {
-		return nil, fmt.Errorf("игра не найдена: %w", err)
+		return nil, fmt.Errorf("ошибка получения игры: %w", err)
 	}
+	s.applyProfileNames(game)
 
 	isPlayer := false
 	for _, player := range game.Players {
@@ -242,7 +524,7 @@ func (s *GameService) ShowGame(req dto.ShowGameRequest) (*dto.OutgoingMessage, e
 	}
 
 	if !isPlayer {
-		return nil, fmt.Errorf("вы не являетесь участником этой игры")
+		return nil, domain.ErrNotParticipant
 	}
 
 	return s.getGameMessage(game, req.UserID), nil
@@ -253,6 +535,7 @@ func (s *GameService) GetActiveGame(userID string) (*dto.OutgoingMessage, error)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения игр пользователя: %w", err)
 	}
+	s.applyProfileNames(games...)
 
 	for _, game := range games {
 		if game.Status == domain.GameStatusActive || game.Status == domain.GameStatusWaiting {
@@ -271,20 +554,289 @@ func (s *GameService) GetActiveGame(userID string) (*dto.OutgoingMessage, error)
 }
 
 func (s *GameService) GetGameByID(gameID string) (*domain.Game, error) {
-	return s.repo.GetByID(gameID)
+	game, err := s.repo.GetByID(gameID)
+	if err != nil {
+		return nil, err
+	}
+	s.applyProfileNames(game)
+	return game, nil
+}
+
+// applyProfileNames подставляет в игры актуальные имена из профилей,
+// чтобы смена имени пользователя отражалась во всех его играх.
+func (s *GameService) applyProfileNames(games ...*domain.Game) {
+	var ids []string
+	for _, game := range games {
+		for _, p := range game.Players {
+			if p.ID != "" {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	profiles, err := s.users.GetByIDs(ids)
+	if err != nil {
+		log.Printf("Не удалось загрузить профили игроков: %v", err)
+		return
+	}
+
+	for _, game := range games {
+		for i := range game.Players {
+			if profile, ok := profiles[game.Players[i].ID]; ok && profile.DisplayName != "" {
+				game.Players[i].Name = profile.DisplayName
+			}
+		}
+	}
 }
 
 func (s *GameService) GetGameNotifications(game *domain.Game) *dto.OutgoingMessages {
-	var messages []dto.OutgoingMessage
+	return dto.NewOutgoingMessages(s.playerMessages(game, nil)...)
+}
 
+// playerMessages возвращает доску для каждого участника, а для игры с общей доской - одну эту доску.
+func (s *GameService) playerMessages(game *domain.Game, outcome *gameOutcome) []dto.OutgoingMessage {
+	if game.IsPublic() {
+		return []dto.OutgoingMessage{*s.gameMessage(game, game.Players[0].ID, outcome)}
+	}
+
+	var messages []dto.OutgoingMessage
 	for _, player := range game.Players {
 		if player.ID != "" {
-			playerMessage := s.getGameMessage(game, player.ID)
-			messages = append(messages, *playerMessage)
+			messages = append(messages, *s.gameMessage(game, player.ID, outcome))
+		}
+	}
+	return messages
+}
+
+// opponentNotifications готовит уведомления для остальных участников игры.
+// Они сохраняются в outbox в одной транзакции с игрой и доставляются диспетчером.
+// У игры с общей доской её обновляет ответ на ход, поэтому уведомления не нужны.
+func (s *GameService) opponentNotifications(game *domain.Game, userID string, outcome *gameOutcome) ([]domain.Notification, error) {
+	if game.IsPublic() {
+		return nil, nil
+	}
+
+	var notifications []domain.Notification
+
+	for _, player := range game.Players {
+		if player.ID == "" || player.ID == userID {
+			continue
+		}
+
+		notification, err := newNotification(s.gameMessage(game, player.ID, outcome))
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func newNotification(msg *dto.OutgoingMessage) (domain.Notification, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return domain.Notification{}, err
+	}
+
+	return domain.Notification{
+		ID:        uuid.New().String(),
+		UserID:    msg.UserID,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// startMatch создаёт рейтинговую игру для пары из очереди быстрой игры и уведомляет
+// обоих игроков, кроме skipUserID.
+func (s *GameService) startMatch(match domain.Match, skipUserID string) (*domain.Game, error) {
+	return s.startGame(
+		uuid.New().String(),
+		domain.Player{ID: match.First.UserID, Name: match.First.UserName},
+		domain.Player{ID: match.Second.UserID, Name: match.Second.UserName},
+		true,
+		skipUserID,
+		domain.GameOrigin{Match: &match},
+	)
+}
+
+// startGame создаёт игру сразу для двух известных игроков. Если у второго задан Symbol, он играет им,
+// иначе символы распределяются случайно. Игра сохраняется уже начатой вместе с закрытием origin.
+func (s *GameService) startGame(gameID string, first, second domain.Player, rated bool, skipUserID string, origin domain.GameOrigin) (*domain.Game, error) {
+	game := domain.NewGame(first.ID, first.Name, rated, "", "")
+	game.ID = gameID
+
+	var err error
+	if second.Symbol != "" {
+		err = game.JoinGameAs(second.ID, second.Name, second.Symbol)
+	} else {
+		err = game.JoinGame(second.ID, second.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.applyProfileNames(game)
+
+	notifications, err := s.opponentNotifications(game, skipUserID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Start(game, origin, notifications...); err != nil {
+		return nil, fmt.Errorf("ошибка создания игры: %w", err)
+	}
+
+	return game, nil
+}
+
+// OnGameFinished подписывает обработчик на завершение игр. Обработчики вызываются после сохранения
+// последнего хода; подписываться нужно до начала обработки запросов.
+func (s *GameService) OnGameFinished(handler func(game *domain.Game)) {
+	s.finishHandlers = append(s.finishHandlers, handler)
+}
+
+// addChatMember запоминает игрока группового чата для таблицы лидеров этого чата.
+func (s *GameService) addChatMember(chatID, userID string) {
+	if chatID == "" {
+		return
+	}
+	if err := s.leaderboard.AddChatMember(chatID, userID); err != nil {
+		log.Printf("Не удалось сохранить участника чата %s: %v", chatID, err)
+	}
+}
+
+// saveMove сохраняет ход вместе с уведомлением соперника. Если ход завершил игру, изменения рейтинга
+// и награды записываются в той же транзакции, а рейтинг считается по заблокированным профилям игроков.
+func (s *GameService) saveMove(game *domain.Game, userID string) (*gameOutcome, error) {
+	if game.Status != domain.GameStatusFinished {
+		notifications, err := s.opponentNotifications(game, userID, nil)
+		if err != nil {
+			return nil, err
 		}
+		return nil, s.repo.Update(game, notifications...)
 	}
 
-	return dto.NewOutgoingMessages(messages...)
+	// награды проверяются до транзакции: settle не должен обращаться к другим репозиториям,
+	// единственное соединение с SQLite в это время занято транзакцией
+	achievements := s.evaluateAchievements(game)
+
+	var outcome *gameOutcome
+	err := s.repo.Finish(game, func(players map[string]*domain.User, award domain.AwardFunc) (*domain.GameSettlement, error) {
+		awarded, err := award(achievements)
+		if err != nil {
+			return nil, err
+		}
+
+		outcome = &gameOutcome{
+			ratingChanges: domain.CalculateRatingChanges(game, players),
+			achievements:  awarded,
+		}
+
+		notifications, err := s.opponentNotifications(game, userID, outcome)
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.GameSettlement{
+			RatingChanges: outcome.ratingChanges,
+			Notifications: notifications,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outcome, nil
+}
+
+func (s *GameService) loadOutcome(game *domain.Game) *gameOutcome {
+	outcome := &gameOutcome{}
+
+	if game.Rated {
+		changes, err := s.ratings.GetChangesByGame(game.ID)
+		if err != nil {
+			log.Printf("Не удалось загрузить изменения рейтинга по игре %s: %v", game.ID, err)
+		}
+		outcome.ratingChanges = changes
+	}
+
+	achievements, err := s.achievements.GetByGame(game.ID)
+	if err != nil {
+		log.Printf("Не удалось загрузить награды по игре %s: %v", game.ID, err)
+	}
+	outcome.achievements = achievements
+
+	return outcome
+}
+
+// evaluateAchievements находит награды, впервые заработанные игроками в этой игре.
+// Ошибки только логируются: без наград игра всё равно должна сохраниться.
+func (s *GameService) evaluateAchievements(game *domain.Game) []domain.UserAchievement {
+	var awarded []domain.UserAchievement
+	now := time.Now()
+
+	for _, player := range game.Players {
+		results, err := s.repo.GetFinishedGamesByUser(player.ID)
+		if err != nil {
+			log.Printf("Не удалось загрузить игры %s для наград: %v", player.ID, err)
+			continue
+		}
+		stats := domain.NewPlayerStats(player.ID, append(results, game.Result()))
+
+		owned, err := s.achievements.GetByUser(player.ID)
+		if err != nil {
+			log.Printf("Не удалось загрузить награды %s: %v", player.ID, err)
+			continue
+		}
+		has := map[domain.AchievementCode]bool{}
+		for _, a := range owned {
+			has[a.Code] = true
+		}
+
+		for _, code := range domain.EvaluateAchievements(game, player.ID, stats) {
+			if has[code] {
+				continue
+			}
+			awarded = append(awarded, domain.UserAchievement{
+				UserID:    player.ID,
+				Code:      code,
+				GameID:    game.ID,
+				AwardedAt: now,
+			})
+		}
+	}
+
+	return awarded
+}
+
+func ratingChangeText(changes []domain.RatingChange, userID string) string {
+	for _, c := range changes {
+		if c.UserID != userID {
+			continue
+		}
+		if c.Delta < 0 {
+			return fmt.Sprintf("\n📉 Рейтинг: %d (%d)", c.After, c.Delta)
+		}
+		return fmt.Sprintf("\n📈 Рейтинг: %d (+%d)", c.After, c.Delta)
+	}
+
+	return ""
+}
+
+func achievementsText(achievements []domain.UserAchievement, userID string) string {
+	var text string
+	for _, a := range achievements {
+		if a.UserID != userID {
+			continue
+		}
+		if achievement, ok := domain.AchievementByCode(a.Code); ok {
+			text += fmt.Sprintf("\n🎖 Новая награда: %s %s", achievement.Icon, achievement.Title)
+		}
+	}
+	return text
 }
 
 func renderBoard(board [3][3]string) string {
@@ -307,22 +859,32 @@ func renderBoard(board [3][3]string) string {
 	return result
 }
 
-func generateMoveButtons(gameID string, board [3][3]string) []dto.Button {
-	var buttons []dto.Button
-	rows := []string{"A", "B", "C"}
-	cols := []string{"1", "2", "3"}
-
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			coord := rows[i] + cols[j]
-			buttons = append(buttons, dto.Button{
-				Text:   coord,
-				Action: "/move " + gameID + " " + coord,
-			})
+// boardButtons строит доску из кнопок: ряд клавиатуры на каждый ряд доски. Если playable,
+// пустые клетки ведут к ходу в них, остальные кнопки не нажимаются.
+func boardButtons(gameID string, board [3][3]string, playable bool) [][]dto.Button {
+	rows := make([][]dto.Button, len(board))
+	for i, line := range board {
+		for j, cell := range line {
+			button := dto.Button{Text: cellText(cell)}
+			if playable && cell == "" {
+				button.Action = fmt.Sprintf("/move %s %c%d", gameID, 'A'+i, j+1)
+			}
+			rows[i] = append(rows[i], button)
 		}
 	}
+	return rows
+}
 
-	return buttons
+// cellText возвращает значок клетки. Telegram не принимает кнопки с пустым текстом,
+// поэтому пустая клетка - невидимый символ.
+func cellText(symbol string) string {
+	switch symbol {
+	case "X":
+		return "✖"
+	case "O":
+		return "⭕"
+	}
+	return "\u2800"
 }
 
 func parseCoordinate(text string) (domain.Coordinate, error) {
@@ -354,12 +916,12 @@ func getOpponentSymbol(symbol string) string {
 }
 
 func getUserDisplayName(userID string) string {
-	if strings.HasPrefix(userID, "chat_") {
-		chatID := strings.TrimPrefix(userID, "chat_")
-		if len(chatID) > 4 {
-			return "Игрок " + chatID[len(chatID)-4:]
+	if strings.HasPrefix(userID, "user_") {
+		telegramID := strings.TrimPrefix(userID, "user_")
+		if len(telegramID) > 4 {
+			return "Игрок " + telegramID[len(telegramID)-4:]
 		}
-		return "Игрок " + chatID
+		return "Игрок " + telegramID
 	}
 
 	if len(userID) > 4 {
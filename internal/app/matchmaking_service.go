@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+// MatchmakingService ведёт очередь быстрой игры. Пары подбираются при каждой постановке в очередь
+// и периодически в Run, чтобы расширившийся со временем диапазон рейтинга сводил уже ожидающих игроков.
+type MatchmakingService struct {
+	queue       domain.MatchmakingRepository
+	games       domain.GameRepository
+	users       domain.UserRepository
+	gameService *GameService
+	interval    time.Duration
+}
+
+func NewMatchmakingService(
+	queue domain.MatchmakingRepository,
+	games domain.GameRepository,
+	users domain.UserRepository,
+	gameService *GameService,
+	interval time.Duration,
+) *MatchmakingService {
+	return &MatchmakingService{
+		queue:       queue,
+		games:       games,
+		users:       users,
+		gameService: gameService,
+		interval:    interval,
+	}
+}
+
+func (s *MatchmakingService) Play(userID, userName string) (*dto.OutgoingMessage, error) {
+	active, err := s.games.GetActiveGamesByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения игр пользователя: %w", err)
+	}
+	if len(active) > 0 {
+		return nil, domain.ErrAlreadyInGame
+	}
+
+	rating := domain.DefaultRating
+	if user, err := s.users.GetByID(userID); err == nil {
+		rating = user.Rating
+	}
+
+	err = s.queue.Enqueue(domain.QueueEntry{
+		UserID:     userID,
+		UserName:   userName,
+		Rating:     rating,
+		EnqueuedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка постановки в очередь: %w", err)
+	}
+
+	game, err := s.MatchOnce(userID)
+	if err != nil {
+		return nil, err
+	}
+	if game != nil {
+		return s.gameService.getGameMessage(game, userID), nil
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("🔍 Ищем соперника с рейтингом около %d...\nМы сообщим, как только игра будет найдена.", rating),
+		[]dto.Button{{Text: "❌ Отменить поиск", Action: "/play cancel"}},
+	), nil
+}
+
+func (s *MatchmakingService) Cancel(userID string) (*dto.OutgoingMessage, error) {
+	removed, err := s.queue.Remove(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выхода из очереди: %w", err)
+	}
+
+	text := "Вы не стоите в очереди."
+	if removed {
+		text = "❌ Поиск соперника отменён."
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		text,
+		[]dto.Button{
+			{Text: "⚡ Быстрая игра", Action: "/play"},
+			{Text: "📋 Список игр", Action: "/list"},
+		},
+	), nil
+}
+
+func (s *MatchmakingService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.MatchOnce(""); err != nil {
+			log.Printf("Ошибка подбора соперников: %v", err)
+		}
+	}
+}
+
+// MatchOnce создаёт игры для подобранных пар. Если среди них есть initiatorID, его игра возвращается,
+// а сам он не получает уведомления: состояние игры придёт ему в ответе на команду. Если кого-то из пары
+// уже забрал параллельный подбор, пара пропускается, а его соперник остаётся в очереди.
+func (s *MatchmakingService) MatchOnce(initiatorID string) (*domain.Game, error) {
+	matches, err := s.queue.FindMatches(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подбора соперников: %w", err)
+	}
+
+	var initiatorGame *domain.Game
+	for _, match := range matches {
+		game, err := s.gameService.startMatch(match, initiatorID)
+		if errors.Is(err, domain.ErrMatchTaken) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Не удалось создать игру для %s и %s: %v", match.First.UserID, match.Second.UserID, err)
+			continue
+		}
+
+		if match.First.UserID == initiatorID || match.Second.UserID == initiatorID {
+			initiatorGame = game
+		}
+	}
+
+	return initiatorGame, nil
+}
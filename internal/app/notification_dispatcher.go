@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+const (
+	notificationLease      = time.Minute
+	notificationMaxBackoff = 10 * time.Minute
+)
+
+// NotificationDispatcher доставляет уведомления из outbox и повторяет неудачные
+// попытки с экспоненциальной задержкой, пока не исчерпан maxAttempts.
+type NotificationDispatcher struct {
+	outbox      domain.NotificationRepository
+	sender      domain.NotificationSender
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewNotificationDispatcher(outbox domain.NotificationRepository, sender domain.NotificationSender, interval time.Duration, batchSize, maxAttempts int) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		outbox:      outbox,
+		sender:      sender,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchOnce(); err != nil {
+			log.Printf("Ошибка доставки уведомлений: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *NotificationDispatcher) DispatchOnce() error {
+	notifications, err := d.outbox.FetchPending(d.batchSize, notificationLease)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range notifications {
+		if err := d.deliver(n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *NotificationDispatcher) deliver(n domain.Notification) error {
+	sendErr := d.sender.Send(n)
+	if sendErr == nil {
+		return d.outbox.MarkSent(n.ID)
+	}
+
+	attempts := n.Attempts + 1
+	if attempts >= d.maxAttempts {
+		log.Printf("Уведомление %s для %s не доставлено после %d попыток: %v", n.ID, n.UserID, attempts, sendErr)
+		return d.outbox.MarkDead(n.ID, sendErr.Error())
+	}
+
+	backoff := notificationMaxBackoff
+	if attempts < 10 {
+		backoff = min(time.Second<<uint(attempts), notificationMaxBackoff)
+	}
+
+	log.Printf("Уведомление %s для %s не доставлено (попытка %d), повтор через %s: %v", n.ID, n.UserID, attempts, backoff, sendErr)
+	return d.outbox.MarkFailed(n.ID, time.Now().Add(backoff), sendErr.Error())
+}
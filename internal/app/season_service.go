@@ -0,0 +1,264 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+// SeasonService ведёт соревновательные сезоны: открывает первый сезон, по окончании сезона
+// сохраняет итоговую таблицу, мягко сбрасывает рейтинги и сообщает игрокам их места.
+type SeasonService struct {
+	seasons     domain.SeasonRepository
+	leaderboard domain.LeaderboardRepository
+	length      time.Duration
+	interval    time.Duration
+}
+
+func NewSeasonService(
+	seasons domain.SeasonRepository,
+	leaderboard domain.LeaderboardRepository,
+	length, interval time.Duration,
+) *SeasonService {
+	return &SeasonService{seasons: seasons, leaderboard: leaderboard, length: length, interval: interval}
+}
+
+func (s *SeasonService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick открывает первый сезон, если сезонов ещё не было, и закрывает текущий, если он закончился.
+func (s *SeasonService) Tick(now time.Time) {
+	current, err := s.seasons.Current()
+	if errors.Is(err, domain.ErrSeasonNotFound) {
+		season := &domain.Season{ID: 1, StartsAt: now, EndsAt: now.Add(s.length)}
+		if err := s.seasons.Create(season); err != nil {
+			log.Printf("Ошибка создания первого сезона: %v", err)
+			return
+		}
+		log.Printf("Начат %s, до %s", season.Name(), season.EndsAt.Format(time.RFC3339))
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка получения текущего сезона: %v", err)
+		return
+	}
+
+	if now.Before(current.EndsAt) {
+		return
+	}
+	if err := s.rollover(current, now); err != nil && !errors.Is(err, domain.ErrSeasonClosed) {
+		log.Printf("Ошибка завершения сезона %d: %v", current.ID, err)
+	}
+}
+
+func (s *SeasonService) rollover(current *domain.Season, now time.Time) error {
+	next := domain.NextSeason(current, s.length, now)
+
+	standings, err := s.seasons.Rollover(current, next, func(standings []domain.SeasonStanding) ([]domain.Notification, error) {
+		var notifications []domain.Notification
+		for _, st := range standings {
+			text := fmt.Sprintf("🏁 %s завершён!\n\nВаше место: %d из %d %s\nРейтинг в новом сезоне: %d → %d\n%s продлится до %s.",
+				current.Name(),
+				st.Rank, len(standings), domain.SeasonBadge(st.Rank),
+				st.Rating, domain.SoftResetRating(st.Rating),
+				next.Name(), next.EndsAt.UTC().Format("02.01.2006"),
+			)
+			notification, err := newNotification(dto.NewOutgoingMessage(
+				st.UserID,
+				text,
+				[]dto.Button{{Text: "📜 Итоги сезона", Action: fmt.Sprintf("/season %d", current.ID)}},
+			))
+			if err != nil {
+				return nil, err
+			}
+			notifications = append(notifications, notification)
+		}
+		return notifications, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%s завершён, в итоговой таблице %d игроков; начат %s", current.Name(), len(standings), next.Name())
+	return nil
+}
+
+// currentStandings строит таблицу идущего сезона: игроки, сыгравшие в нём рейтинговые игры, по рейтингу.
+func (s *SeasonService) currentStandings(season *domain.Season, limit int) ([]domain.SeasonStanding, error) {
+	entries, err := s.leaderboard.GetLeaderboard(domain.LeaderboardFilter{
+		Metric: domain.LeaderboardByRating,
+		Since:  season.StartsAt,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения таблицы сезона: %w", err)
+	}
+
+	standings := make([]domain.SeasonStanding, len(entries))
+	for i, e := range entries {
+		standings[i] = domain.SeasonStanding{
+			SeasonID:    season.ID,
+			UserID:      e.UserID,
+			DisplayName: e.DisplayName,
+			Rank:        i + 1,
+			Rating:      e.Rating,
+		}
+	}
+	return standings, nil
+}
+
+// Show показывает текущий сезон с местом пользователя или, если в args указан номер, итоги прошедшего сезона.
+func (s *SeasonService) Show(userID, args string) (*dto.OutgoingMessage, error) {
+	current, err := s.seasons.Current()
+	if errors.Is(err, domain.ErrSeasonNotFound) {
+		return dto.NewOutgoingMessage(userID, "🏁 Сезоны ещё не начались.", nil), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения текущего сезона: %w", err)
+	}
+
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return s.showCurrent(userID, current)
+	}
+
+	id, err := strconv.Atoi(args)
+	if err != nil {
+		return nil, domain.ErrSeasonNotFound
+	}
+	if id == current.ID {
+		return s.showCurrent(userID, current)
+	}
+
+	season, err := s.seasons.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.showArchived(userID, season)
+}
+
+func (s *SeasonService) showCurrent(userID string, season *domain.Season) (*dto.OutgoingMessage, error) {
+	standings, err := s.currentStandings(season, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏁 %s\n", season.Name())
+	fmt.Fprintf(&b, "⏳ До конца: %s (%s UTC)\n\n",
+		formatRemaining(time.Until(season.EndsAt)), season.EndsAt.UTC().Format("02.01.2006 15:04"))
+
+	placed := false
+	for _, st := range standings {
+		if st.UserID == userID {
+			fmt.Fprintf(&b, "📈 Ваше место: %d из %d %s, рейтинг %d\n\n", st.Rank, len(standings), domain.SeasonBadge(st.Rank), st.Rating)
+			placed = true
+			break
+		}
+	}
+	if !placed {
+		b.WriteString("Вы ещё не играли рейтинговых игр в этом сезоне.\n\n")
+	}
+
+	b.WriteString(seasonStandingsText(standings))
+	fmt.Fprintf(&b, "\n\nВ конце сезона рейтинг каждого игрока приближается к %d: остаётся %d%% отклонения.",
+		domain.DefaultRating, domain.SeasonCarryOverPercent)
+
+	return dto.NewOutgoingMessage(userID, b.String(), seasonButtons(season, "/top season")), nil
+}
+
+func (s *SeasonService) showArchived(userID string, season *domain.Season) (*dto.OutgoingMessage, error) {
+	standings, err := s.seasons.GetStandings(season.ID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения итогов сезона: %w", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📜 Итоги: %s (%s — %s)\n\n",
+		season.Name(), season.StartsAt.UTC().Format("02.01.2006"), season.EndsAt.UTC().Format("02.01.2006"))
+	b.WriteString(seasonStandingsText(standings))
+
+	for _, st := range standings {
+		if st.UserID == userID {
+			fmt.Fprintf(&b, "\n\nВаше место: %d из %d %s", st.Rank, len(standings), domain.SeasonBadge(st.Rank))
+			break
+		}
+	}
+
+	return dto.NewOutgoingMessage(userID, b.String(), seasonButtons(season, "/season")), nil
+}
+
+func seasonStandingsText(standings []domain.SeasonStanding) string {
+	if len(standings) == 0 {
+		return "Пока никто не попал в таблицу."
+	}
+
+	lines := []string{"Лидеры:"}
+	for i, st := range standings {
+		if i == leaderboardSize {
+			break
+		}
+		name := st.DisplayName
+		if name == "" {
+			name = getUserDisplayName(st.UserID)
+		}
+		place := fmt.Sprintf("%d.", st.Rank)
+		if badge := domain.SeasonBadge(st.Rank); badge != "" {
+			place = badge + " " + place
+		}
+		lines = append(lines, fmt.Sprintf("%s %s — %d", place, name, st.Rating))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func seasonButtons(season *domain.Season, action string) []dto.Button {
+	text := "🏆 Топ сезона"
+	if action == "/season" {
+		text = "🏁 Текущий сезон"
+	}
+
+	buttons := []dto.Button{{Text: text, Action: action}}
+	if season.ID > 1 {
+		buttons = append(buttons, dto.Button{
+			Text:   fmt.Sprintf("📜 Сезон %d", season.ID-1),
+			Action: fmt.Sprintf("/season %d", season.ID-1),
+		})
+	}
+	return buttons
+}
+
+func formatRemaining(d time.Duration) string {
+	if d <= 0 {
+		return "подводятся итоги"
+	}
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d д %d ч", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d ч %d мин", hours, minutes)
+	}
+	return fmt.Sprintf("%d мин", minutes)
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+type StatsService struct {
+	games        domain.GameRepository
+	users        domain.UserRepository
+	leaderboard  domain.LeaderboardRepository
+	achievements domain.AchievementRepository
+	seasons      domain.SeasonRepository
+}
+
+func NewStatsService(
+	games domain.GameRepository,
+	users domain.UserRepository,
+	leaderboard domain.LeaderboardRepository,
+	achievements domain.AchievementRepository,
+	seasons domain.SeasonRepository,
+) *StatsService {
+	return &StatsService{games: games, users: users, leaderboard: leaderboard, achievements: achievements, seasons: seasons}
+}
+
+// ShowStats показывает статистику пользователя; target - @username другого игрока или пустая строка.
+func (s *StatsService) ShowStats(userID, target string) (*dto.OutgoingMessage, error) {
+	subjectID := userID
+	name := ""
+
+	if target != "" {
+		user, err := s.users.GetByUsername(strings.TrimPrefix(target, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка поиска пользователя %s: %w", target, err)
+		}
+		subjectID = user.ID
+		name = user.DisplayName
+	} else if user, err := s.users.GetByID(userID); err == nil {
+		name = user.DisplayName
+	}
+	if name == "" {
+		name = getUserDisplayName(subjectID)
+	}
+
+	results, err := s.games.GetFinishedGamesByUser(subjectID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения завершённых игр: %w", err)
+	}
+
+	buttons := []dto.Button{
+		{Text: "🆕 Создать игру", Action: "/new"},
+		{Text: "📋 Список игр", Action: "/list"},
+	}
+	if subjectID != userID {
+		buttons = append(buttons,
+			dto.Button{Text: "⚔️ Вызвать на игру", Action: "/challenge " + subjectID},
+			dto.Button{Text: "➕ В друзья", Action: "/friends add " + subjectID},
+		)
+	}
+
+	if len(results) == 0 {
+		return dto.NewOutgoingMessage(
+			userID,
+			fmt.Sprintf("📊 Статистика: %s\n\nЗавершённых игр пока нет.", name),
+			buttons,
+		), nil
+	}
+
+	stats := domain.NewPlayerStats(subjectID, results)
+
+	text := fmt.Sprintf(`📊 Статистика: %s
+
+🎮 Игр: %d
+🏆 Побед: %d · 😔 Поражений: %d · 🤝 Ничьих: %d
+📈 Процент побед: %s
+✖ За X: %d из %d (%s)
+⭕ За O: %d из %d (%s)
+🔥 Текущая серия побед: %d
+⭐ Лучшая серия побед: %d
+⏱ Средняя длина игры: %.1f хода`,
+		name,
+		stats.Games,
+		stats.Wins, stats.Losses, stats.Draws,
+		formatPercent(stats.Wins, stats.Games),
+		stats.WinsAsX, stats.GamesAsX, formatPercent(stats.WinsAsX, stats.GamesAsX),
+		stats.WinsAsO, stats.GamesAsO, formatPercent(stats.WinsAsO, stats.GamesAsO),
+		stats.CurrentStreak,
+		stats.BestStreak,
+		stats.AverageMoves,
+	)
+
+	return dto.NewOutgoingMessage(userID, text, buttons), nil
+}
+
+// ShowProfile показывает профиль пользователя с рейтингом и наградами; target - другой игрок или пустая строка.
+func (s *StatsService) ShowProfile(userID, target string) (*dto.OutgoingMessage, error) {
+	var user *domain.User
+	var err error
+	if target != "" {
+		user, err = findUser(s.users, target)
+	} else {
+		user, err = s.users.GetByID(userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска пользователя: %w", err)
+	}
+
+	earned, err := s.achievements.GetByUser(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения наград: %w", err)
+	}
+	awardedAt := make(map[domain.AchievementCode]time.Time, len(earned))
+	for _, a := range earned {
+		awardedAt[a.Code] = a.AwardedAt
+	}
+
+	var lines []string
+	for _, a := range domain.Achievements {
+		if at, ok := awardedAt[a.Code]; ok {
+			lines = append(lines, fmt.Sprintf("%s %s — %s (%s)", a.Icon, a.Title, a.Description, at.Format("02.01.2006")))
+		} else {
+			lines = append(lines, fmt.Sprintf("🔒 %s — %s", a.Title, a.Description))
+		}
+	}
+
+	seasons, err := s.seasons.GetUserStandings(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения итогов сезонов: %w", err)
+	}
+
+	text := fmt.Sprintf(`👤 Профиль: %s
+
+📈 Рейтинг: %d (рейтинговых игр: %d)
+
+🎖 Награды: %d из %d
+%s`,
+		profileName(user),
+		user.Rating, user.RatedGames,
+		len(earned), len(domain.Achievements),
+		strings.Join(lines, "\n"),
+	)
+
+	if len(seasons) > 0 {
+		lines = nil
+		for _, st := range seasons {
+			line := fmt.Sprintf("Сезон %d — %d место, рейтинг %d", st.SeasonID, st.Rank, st.Rating)
+			if badge := domain.SeasonBadge(st.Rank); badge != "" {
+				line = badge + " " + line
+			}
+			lines = append(lines, line)
+		}
+		text += "\n\n🏁 Сезоны:\n" + strings.Join(lines, "\n")
+	}
+
+	buttons := []dto.Button{
+		{Text: "📊 Статистика", Action: "/stats"},
+		{Text: "🏆 Топ", Action: "/top"},
+	}
+	if user.ID != userID {
+		buttons = []dto.Button{
+			{Text: "⚔️ Вызвать на игру", Action: "/challenge " + user.ID},
+			{Text: "➕ В друзья", Action: "/friends add " + user.ID},
+		}
+	}
+
+	return dto.NewOutgoingMessage(userID, text, buttons), nil
+}
+
+func formatPercent(part, total int) string {
+	if total == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%d%%", part*100/total)
+}
+
+const leaderboardSize = 10
+
+// ShowLeaderboard показывает таблицу лидеров. args - метрика (rating, wins) и период (all, month, week, season) в любом порядке,
+// chatID - групповой чат, в котором запрошена таблица, или пустая строка для общей.
+func (s *StatsService) ShowLeaderboard(userID, chatID, args string) (*dto.OutgoingMessage, error) {
+	metric := domain.LeaderboardByRating
+	period := domain.PeriodAllTime
+
+	for _, arg := range strings.Fields(strings.ToLower(args)) {
+		switch arg {
+		case string(domain.LeaderboardByRating), string(domain.LeaderboardByWins):
+			metric = domain.LeaderboardMetric(arg)
+		case string(domain.PeriodAllTime), string(domain.PeriodMonth), string(domain.PeriodWeek), string(domain.PeriodSeason):
+			period = domain.LeaderboardPeriod(arg)
+		}
+	}
+
+	since := period.Since(time.Now())
+	if period == domain.PeriodSeason {
+		season, err := s.seasons.Current()
+		if err != nil && !errors.Is(err, domain.ErrSeasonNotFound) {
+			return nil, fmt.Errorf("ошибка получения текущего сезона: %w", err)
+		}
+		if season != nil {
+			since = season.StartsAt
+		}
+	}
+
+	entries, err := s.leaderboard.GetLeaderboard(domain.LeaderboardFilter{
+		Metric: metric,
+		Since:  since,
+		ChatID: chatID,
+		Limit:  leaderboardSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения таблицы лидеров: %w", err)
+	}
+
+	title := "🏆 Топ по рейтингу"
+	if metric == domain.LeaderboardByWins {
+		title = "🏆 Топ по победам"
+	}
+	switch period {
+	case domain.PeriodMonth:
+		title += " за месяц"
+	case domain.PeriodWeek:
+		title += " за неделю"
+	case domain.PeriodSeason:
+		title += " за сезон"
+	default:
+		title += " за всё время"
+	}
+	if chatID != "" {
+		title += " в этом чате"
+	}
+
+	var lines []string
+	medals := []string{"🥇", "🥈", "🥉"}
+	for i, e := range entries {
+		place := fmt.Sprintf("%d.", i+1)
+		if i < len(medals) {
+			place = medals[i]
+		}
+
+		name := e.DisplayName
+		if name == "" {
+			name = getUserDisplayName(e.UserID)
+		}
+
+		if metric == domain.LeaderboardByWins {
+			lines = append(lines, fmt.Sprintf("%s %s — побед: %d", place, name, e.Wins))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %s — %d", place, name, e.Rating))
+		}
+	}
+
+	text := title + "\n\n"
+	if len(lines) == 0 {
+		text += "Пока никто не попал в таблицу."
+	} else {
+		text += strings.Join(lines, "\n")
+	}
+
+	otherMetric, otherMetricText := domain.LeaderboardByWins, "🎯 По победам"
+	if metric == domain.LeaderboardByWins {
+		otherMetric, otherMetricText = domain.LeaderboardByRating, "📈 По рейтингу"
+	}
+
+	buttons := []dto.Button{
+		{Text: otherMetricText, Action: fmt.Sprintf("/top %s %s", otherMetric, period)},
+	}
+	periods := []struct {
+		period domain.LeaderboardPeriod
+		text   string
+	}{
+		{domain.PeriodAllTime, "Всё время"},
+		{domain.PeriodMonth, "Месяц"},
+		{domain.PeriodWeek, "Неделя"},
+		{domain.PeriodSeason, "Сезон"},
+	}
+	for _, p := range periods {
+		if p.period != period {
+			buttons = append(buttons, dto.Button{Text: p.text, Action: fmt.Sprintf("/top %s %s", metric, p.period)})
+		}
+	}
+
+	return dto.NewOutgoingMessage(userID, text, buttons), nil
+}
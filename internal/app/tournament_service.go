@@ -0,0 +1,945 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+const (
+	maxTournamentDelay   = 7 * 24 * time.Hour
+	defaultArenaDuration = 30 * time.Minute
+	maxArenaDuration     = 24 * time.Hour
+)
+
+// TournamentService проводит турниры: закрывает регистрацию в назначенное время, создаёт партии туров,
+// продвигает турнир после каждой сыгранной партии и рассылает таблицу после каждого тура.
+// Результаты приходят из GameService.OnGameFinished, а Run подбирает пропущенные, например после перезапуска.
+// На партию тура отводится roundTime: партию, не доигранную к сроку, проигрывает тот, чей был ход.
+type TournamentService struct {
+	tournaments domain.TournamentRepository
+	games       domain.GameRepository
+	users       domain.UserRepository
+	gameService *GameService
+	interval    time.Duration
+	roundTime   time.Duration
+
+	mu sync.Mutex
+}
+
+func NewTournamentService(
+	tournaments domain.TournamentRepository,
+	games domain.GameRepository,
+	users domain.UserRepository,
+	gameService *GameService,
+	interval time.Duration,
+	roundTime time.Duration,
+) *TournamentService {
+	return &TournamentService{
+		tournaments: tournaments,
+		games:       games,
+		users:       users,
+		gameService: gameService,
+		interval:    interval,
+		roundTime:   roundTime,
+	}
+}
+
+// Create создаёт турнир; args - формат, через сколько минут старт и необязательное название.
+// Для швейцарской системы после минут можно указать число туров, для арены - её длительность в минутах.
+func (s *TournamentService) Create(userID, userName, args string) (*dto.OutgoingMessage, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return nil, domain.ErrInvalidTournament
+	}
+
+	format, err := domain.ParseTournamentFormat(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	minutes, err := strconv.Atoi(fields[1])
+	delay := time.Duration(minutes) * time.Minute
+	if err != nil || minutes < 1 || delay > maxTournamentDelay {
+		return nil, domain.ErrInvalidTournament
+	}
+
+	rounds := 0
+	duration := time.Duration(0)
+	nameFields := fields[2:]
+	if format == domain.TournamentArena {
+		duration = defaultArenaDuration
+	}
+	if len(nameFields) > 0 {
+		n, err := strconv.Atoi(nameFields[0])
+		switch {
+		case err != nil:
+		case format == domain.TournamentSwiss:
+			if n < 1 || n >= domain.TournamentMaxPlayers {
+				return nil, domain.ErrInvalidTournament
+			}
+			rounds = n
+			nameFields = nameFields[1:]
+		case format == domain.TournamentArena:
+			duration = time.Duration(n) * time.Minute
+			if n < 1 || duration > maxArenaDuration {
+				return nil, domain.ErrInvalidTournament
+			}
+			nameFields = nameFields[1:]
+		}
+	}
+
+	t, err := domain.NewTournament(userID, userName, strings.Join(nameFields, " "), format, time.Now().Add(delay))
+	if err != nil {
+		return nil, err
+	}
+	t.ID = uuid.New().String()
+	t.Rounds = rounds
+	t.Duration = duration
+
+	if err := s.tournaments.Create(t); err != nil {
+		return nil, fmt.Errorf("ошибка создания турнира: %w", err)
+	}
+
+	formatText := formatName(t.Format)
+	if t.Rounds > 0 {
+		formatText += fmt.Sprintf(", туров: %d", t.Rounds)
+	}
+	if t.Duration > 0 {
+		formatText += fmt.Sprintf(", длительность %s", formatTTL(t.Duration))
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("🏆 Турнир «%s» создан!\nФормат: %s\nСтарт через %s, до этого открыта регистрация.\n\nЧтобы участвовать: /tournament join %s",
+			t.Name, formatText, formatTTL(delay), t.ID),
+		[]dto.Button{
+			{Text: "✅ Участвовать", Action: "/tournament join " + t.ID},
+			{Text: "📋 Турнир", Action: "/tournament " + t.ID},
+		},
+	), nil
+}
+
+func (s *TournamentService) Join(userID, userName, tournamentID string) (*dto.OutgoingMessage, error) {
+	t, err := s.tournaments.GetByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.CheckRegistration(time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.tournaments.AddPlayer(domain.TournamentPlayer{
+		TournamentID: t.ID,
+		UserID:       userID,
+		UserName:     userName,
+		JoinedAt:     time.Now(),
+	}, domain.TournamentMaxPlayers)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("✅ Вы записаны на турнир «%s». Старт через %s.", t.Name, formatTTL(time.Until(t.StartsAt))),
+		[]dto.Button{
+			{Text: "❌ Отказаться", Action: "/tournament leave " + t.ID},
+			{Text: "📋 Турнир", Action: "/tournament " + t.ID},
+		},
+	), nil
+}
+
+func (s *TournamentService) Leave(userID, tournamentID string) (*dto.OutgoingMessage, error) {
+	t, err := s.tournaments.GetByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.CheckRegistration(time.Now()); err != nil {
+		return nil, err
+	}
+
+	removed, err := s.tournaments.RemovePlayer(t.ID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отмены регистрации: %w", err)
+	}
+	if !removed {
+		return nil, domain.ErrNotRegistered
+	}
+
+	return dto.NewOutgoingMessage(
+		userID,
+		fmt.Sprintf("Вы больше не участвуете в турнире «%s».", t.Name),
+		[]dto.Button{{Text: "🏆 Турниры", Action: "/tournaments"}},
+	), nil
+}
+
+// List показывает турниры, открытые для регистрации и идущие сейчас.
+func (s *TournamentService) List(userID string) (*dto.OutgoingMessage, error) {
+	tournaments, err := s.tournaments.ListByStatus(domain.TournamentRegistration, domain.TournamentRunning)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения турниров: %w", err)
+	}
+
+	if len(tournaments) == 0 {
+		return dto.NewOutgoingMessage(
+			userID,
+			"Турниров пока нет.\n\nСоздать: /tournament new round 30 Название - круговой турнир через 30 минут, "+
+				"/tournament new knockout 30 - на выбывание, /tournament new swiss 30 5 - швейцарская система в 5 туров, "+
+				"/tournament new arena 30 60 - арена на час.",
+			nil,
+		), nil
+	}
+
+	var lines []string
+	var buttons []dto.Button
+	for _, t := range tournaments {
+		state := fmt.Sprintf("старт через %s", formatTTL(time.Until(t.StartsAt)))
+		if t.Status == domain.TournamentRunning {
+			state = runningState(t)
+		}
+		lines = append(lines, fmt.Sprintf("• «%s» - %s, %s", t.Name, formatName(t.Format), state))
+		buttons = append(buttons, dto.Button{Text: "📋 " + t.Name, Action: "/tournament " + t.ID})
+	}
+
+	return dto.NewOutgoingMessage(userID, "🏆 Турниры\n\n"+strings.Join(lines, "\n"), buttons), nil
+}
+
+// Show показывает участников турнира, а после старта - таблицу и пары текущего тура.
+func (s *TournamentService) Show(userID, tournamentID string) (*dto.OutgoingMessage, error) {
+	t, err := s.tournaments.GetByID(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := s.tournaments.GetPlayers(t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения участников турнира: %w", err)
+	}
+
+	text := fmt.Sprintf("🏆 «%s» - %s\n", t.Name, formatName(t.Format))
+	var buttons []dto.Button
+
+	switch t.Status {
+	case domain.TournamentRegistration:
+		text += fmt.Sprintf("Регистрация, старт через %s.\n\nУчастники (%d из %d):",
+			formatTTL(time.Until(t.StartsAt)), len(players), domain.TournamentMaxPlayers)
+		for _, p := range players {
+			text += "\n• " + playerName(p)
+		}
+		buttons = []dto.Button{
+			{Text: "✅ Участвовать", Action: "/tournament join " + t.ID},
+			{Text: "❌ Отказаться", Action: "/tournament leave " + t.ID},
+		}
+
+	case domain.TournamentCancelled:
+		text += "Турнир отменён."
+
+	default:
+		games, err := s.tournaments.GetGames(t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка получения партий турнира: %w", err)
+		}
+
+		if t.Status == domain.TournamentFinished {
+			text += "Турнир завершён.\n\n"
+		} else {
+			text += showState(t) + "\n\n"
+		}
+		text += standingsText(t, players, games)
+
+		if t.Status == domain.TournamentRunning && t.Format == domain.TournamentArena {
+			if playing := arenaGamesText(players, games); playing != "" {
+				text += "\n\nСейчас играют:\n" + playing
+			}
+		} else if t.Status == domain.TournamentRunning {
+			text += fmt.Sprintf("\n\nПары тура %d:\n%s", t.Round, roundText(players, games, t.Round))
+		}
+	}
+
+	buttons = append(buttons, dto.Button{Text: "🔄 Обновить", Action: "/tournament " + t.ID})
+	return dto.NewOutgoingMessage(userID, text, buttons), nil
+}
+
+// HandleGameFinished засчитывает результат турнирной партии и, если тур сыгран, начинает следующий.
+func (s *TournamentService) HandleGameFinished(game *domain.Game) {
+	tournamentID, err := s.tournaments.RecordResult(game.ID, gameWinnerID(game))
+	if err != nil {
+		log.Printf("Не удалось записать результат турнирной игры %s: %v", game.ID, err)
+		return
+	}
+	if tournamentID == "" {
+		return
+	}
+
+	if err := s.advance(tournamentID, time.Now()); err != nil {
+		log.Printf("Ошибка продвижения турнира %s: %v", tournamentID, err)
+	}
+}
+
+func (s *TournamentService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.Tick(time.Now())
+	}
+}
+
+// Tick запускает турниры, у которых наступило время старта, и досчитывает результаты идущих.
+func (s *TournamentService) Tick(now time.Time) {
+	registration, err := s.tournaments.ListByStatus(domain.TournamentRegistration)
+	if err != nil {
+		log.Printf("Ошибка получения турниров: %v", err)
+		return
+	}
+	for _, t := range registration {
+		if now.Before(t.StartsAt) {
+			continue
+		}
+		if err := s.start(t); err != nil {
+			log.Printf("Ошибка старта турнира %s: %v", t.ID, err)
+		}
+	}
+
+	running, err := s.tournaments.ListByStatus(domain.TournamentRunning)
+	if err != nil {
+		log.Printf("Ошибка получения турниров: %v", err)
+		return
+	}
+	for _, t := range running {
+		if err := s.syncResults(t, now); err != nil {
+			log.Printf("Ошибка сверки результатов турнира %s: %v", t.ID, err)
+			continue
+		}
+		if err := s.advance(t.ID, now); err != nil {
+			log.Printf("Ошибка продвижения турнира %s: %v", t.ID, err)
+		}
+	}
+}
+
+func (s *TournamentService) start(t *domain.Tournament) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	players, err := s.tournaments.GetPlayers(t.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(players) < domain.TournamentMinPlayers {
+		return s.cancel(t, players)
+	}
+
+	ratings := make(map[string]int, len(players))
+	for _, p := range players {
+		ratings[p.UserID] = domain.DefaultRating
+		if user, err := s.users.GetByID(p.UserID); err == nil {
+			ratings[p.UserID] = user.Rating
+		}
+	}
+	players = domain.SeedPlayers(players, ratings)
+	if err := s.tournaments.SetSeeds(players); err != nil {
+		return err
+	}
+
+	t.Status = domain.TournamentRunning
+	t.Round = 1
+
+	intro := ""
+	switch t.Format {
+	case domain.TournamentArena:
+		intro = fmt.Sprintf("🏁 Арена «%s» началась! Участников: %d, продлится %s. Новый соперник подбирается сразу после каждой партии.",
+			t.Name, len(players), formatTTL(t.Duration))
+	case domain.TournamentSwiss:
+		t.Rounds = domain.SwissRounds(t.Rounds, len(players))
+	default:
+		t.Rounds = domain.TournamentRounds(t.Format, len(players))
+	}
+	if intro == "" {
+		intro = fmt.Sprintf("🏁 Турнир «%s» начался! Участников: %d, туров: %d.", t.Name, len(players), t.Rounds)
+	}
+	return s.startRound(t, 0, players, players, s.pairings(t, players, nil), intro)
+}
+
+func (s *TournamentService) cancel(t *domain.Tournament, players []domain.TournamentPlayer) error {
+	now := time.Now()
+	t.Status = domain.TournamentCancelled
+	t.FinishedAt = &now
+
+	recipients := map[string]bool{t.OrganizerID: true}
+	for _, p := range players {
+		recipients[p.UserID] = true
+	}
+
+	var notifications []domain.Notification
+	for userID := range recipients {
+		notification, err := newNotification(dto.NewOutgoingMessage(
+			userID,
+			fmt.Sprintf("❌ Турнир «%s» отменён: записалось меньше %d участников.", t.Name, domain.TournamentMinPlayers),
+			[]dto.Button{{Text: "🏆 Турниры", Action: "/tournaments"}},
+		))
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return s.ignoreRoundDone(s.tournaments.Finish(t, notifications...))
+}
+
+// advance начинает следующий тур или завершает турнир, когда все партии текущего тура сыграны.
+func (s *TournamentService) advance(tournamentID string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.tournaments.GetByID(tournamentID)
+	if err != nil {
+		return err
+	}
+	if t.Status != domain.TournamentRunning {
+		return nil
+	}
+
+	players, err := s.tournaments.GetPlayers(t.ID)
+	if err != nil {
+		return err
+	}
+	games, err := s.tournaments.GetGames(t.ID)
+	if err != nil {
+		return err
+	}
+
+	if t.Format == domain.TournamentArena {
+		return s.advanceArena(t, players, games, now)
+	}
+
+	if !domain.RoundFinished(games, t.Round) {
+		return nil
+	}
+
+	if t.Format == domain.TournamentKnockout {
+		if replays := domain.KnockoutReplays(games, t.Round); len(replays) > 0 {
+			intro := fmt.Sprintf("🤝 Ничья в туре %d турнира «%s» - партия переигрывается.", t.Round, t.Name)
+			return s.startRound(t, t.Round, players, pairedPlayers(players, replays), replays, intro)
+		}
+	}
+
+	if s.lastRound(t, players, games) {
+		return s.finish(t, players, games)
+	}
+
+	t.Round++
+	intro := fmt.Sprintf("📊 Тур %d турнира «%s» завершён.\n\n%s", t.Round-1, t.Name, standingsText(t, players, games))
+	return s.startRound(t, t.Round-1, players, players, s.pairings(t, players, games), intro)
+}
+
+// advanceArena сводит освободившихся участников арены, пока не вышло время. По окончании времени
+// арена завершается сразу: недоигранные партии закрываются ничьей, иначе одна брошенная
+// партия не дала бы ей закончиться.
+func (s *TournamentService) advanceArena(
+	t *domain.Tournament,
+	players []domain.TournamentPlayer,
+	games []domain.TournamentGame,
+	now time.Time,
+) error {
+	if !now.Before(t.EndsAt()) {
+		if domain.RoundFinished(games, t.Round) {
+			return s.finish(t, players, games)
+		}
+
+		for _, g := range games {
+			if g.Finished || g.GameID == "" {
+				continue
+			}
+			if err := s.drawUnfinished(t, g); err != nil {
+				return err
+			}
+		}
+
+		// партия могла завершиться по-настоящему одновременно с этим, поэтому итоги берутся из хранилища
+		games, err := s.tournaments.GetGames(t.ID)
+		if err != nil {
+			return err
+		}
+		return s.finish(t, players, games)
+	}
+
+	pairs := domain.ArenaPairings(players, games)
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	intro := fmt.Sprintf("⚔️ Арена «%s», до конца %s.", t.Name, formatTTL(t.EndsAt().Sub(now)))
+	return s.startRound(t, t.Round, players, pairedPlayers(players, pairs), pairs, intro)
+}
+
+// drawUnfinished засчитывает ничьей партию арены, которую не доиграли до конца арены, и закрывает её игру.
+func (s *TournamentService) drawUnfinished(t *domain.Tournament, g domain.TournamentGame) error {
+	game, err := s.games.GetByID(g.GameID)
+	if errors.Is(err, domain.ErrGameNotFound) {
+		// игра уже в архиве или не была создана, закрывать нечего
+		result, _, err := s.archivedResult(g)
+		if err != nil {
+			return err
+		}
+		_, err = s.tournaments.RecordResult(g.GameID, result.WinnerID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if game.Status != domain.GameStatusActive {
+		_, err = s.tournaments.RecordResult(game.ID, gameWinnerID(game))
+		return err
+	}
+	return s.timeOut(t, game, "")
+}
+
+func (s *TournamentService) finish(t *domain.Tournament, players []domain.TournamentPlayer, games []domain.TournamentGame) error {
+	now := time.Now()
+	t.Status = domain.TournamentFinished
+	t.FinishedAt = &now
+
+	standings := domain.ComputeStandings(t.Format, players, games)
+	text := fmt.Sprintf("🏆 Турнир «%s» завершён!\n\n%s\n\n🥇 Победитель: %s",
+		t.Name, standingsText(t, players, games), playerName(standings[0].Player))
+
+	notifications, err := tournamentNotifications(t, players, func(domain.TournamentPlayer) string { return text })
+	if err != nil {
+		return err
+	}
+	return s.ignoreRoundDone(s.tournaments.Finish(t, notifications...))
+}
+
+func (s *TournamentService) lastRound(t *domain.Tournament, players []domain.TournamentPlayer, games []domain.TournamentGame) bool {
+	if t.Format == domain.TournamentKnockout {
+		return len(domain.KnockoutAlive(players, games)) <= 1
+	}
+	return t.Round >= t.Rounds
+}
+
+func (s *TournamentService) pairings(t *domain.Tournament, players []domain.TournamentPlayer, games []domain.TournamentGame) [][2]string {
+	switch t.Format {
+	case domain.TournamentKnockout:
+		return domain.KnockoutPairings(domain.KnockoutAlive(players, games))
+	case domain.TournamentSwiss:
+		return domain.SwissPairings(players, games)
+	case domain.TournamentArena:
+		return domain.ArenaPairings(players, games)
+	}
+	return domain.RoundRobinPairings(players, t.Round)
+}
+
+// startRound сохраняет партии тура вместе с уведомлениями участникам и создаёт игры.
+// fromRound - тур, в котором турнир должен находиться, чтобы параллельный вызов не создал тур дважды.
+func (s *TournamentService) startRound(
+	t *domain.Tournament,
+	fromRound int,
+	players, recipients []domain.TournamentPlayer,
+	pairs [][2]string,
+	intro string,
+) error {
+	now := time.Now()
+
+	var games []domain.TournamentGame
+	for _, pair := range pairs {
+		g := domain.TournamentGame{
+			ID:           uuid.New().String(),
+			TournamentID: t.ID,
+			Round:        t.Round,
+			FirstID:      pair[0],
+			SecondID:     pair[1],
+			CreatedAt:    now,
+		}
+		if g.IsBye() {
+			g.Finished = true
+			g.WinnerID = g.FirstID
+		} else {
+			g.GameID = uuid.New().String()
+		}
+		games = append(games, g)
+	}
+
+	deadline := ""
+	if t.Format != domain.TournamentArena && s.roundTime > 0 {
+		deadline = fmt.Sprintf("\nНа партию - %s: если не сделать ход вовремя, засчитывается поражение.", formatTTL(s.roundTime))
+	}
+	notifications, err := tournamentNotifications(t, recipients, func(p domain.TournamentPlayer) string {
+		text := intro + "\n\n" + pairingText(t, players, games, p.UserID)
+		for _, g := range games {
+			if g.Involves(p.UserID) && !g.IsBye() {
+				return text + deadline
+			}
+		}
+		return text
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.tournaments.StartRound(t, fromRound, games, notifications...); err != nil {
+		return s.ignoreRoundDone(err)
+	}
+
+	for _, g := range games {
+		if g.GameID != "" {
+			s.startGame(g, players)
+		}
+	}
+
+	return nil
+}
+
+func (s *TournamentService) startGame(g domain.TournamentGame, players []domain.TournamentPlayer) {
+	names := playerNames(players)
+	_, err := s.gameService.startGame(
+		g.GameID,
+		domain.Player{ID: g.FirstID, Name: names[g.FirstID]},
+		domain.Player{ID: g.SecondID, Name: names[g.SecondID], Symbol: "O"},
+		false,
+		"",
+		domain.GameOrigin{},
+	)
+	if err != nil {
+		log.Printf("Не удалось создать турнирную игру %s: %v", g.GameID, err)
+	}
+}
+
+// syncResults досчитывает партии текущего тура, о завершении которых сервис не узнал, засчитывает
+// поражение тем, кто не сделал ход до конца отведённого на партию времени, и создаёт игры,
+// которые не успели создаться при старте тура.
+func (s *TournamentService) syncResults(t *domain.Tournament, now time.Time) error {
+	games, err := s.tournaments.GetGames(t.ID)
+	if err != nil {
+		return err
+	}
+
+	var players []domain.TournamentPlayer
+	for _, g := range games {
+		if g.Round != t.Round || g.Finished || g.GameID == "" {
+			continue
+		}
+
+		game, err := s.games.GetByID(g.GameID)
+		if err == nil {
+			switch {
+			case game.Status == domain.GameStatusFinished:
+				if _, err := s.tournaments.RecordResult(game.ID, gameWinnerID(game)); err != nil {
+					return err
+				}
+			case t.Format != domain.TournamentArena && s.roundTime > 0 && !now.Before(g.CreatedAt.Add(s.roundTime)):
+				if err := s.forfeitIdle(t, game); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !errors.Is(err, domain.ErrGameNotFound) {
+			return err
+		}
+
+		// Игры нет среди текущих: либо она уже в архиве, либо не была создана.
+		result, found, err := s.archivedResult(g)
+		if err != nil {
+			return err
+		}
+		if found {
+			if _, err := s.tournaments.RecordResult(g.GameID, result.WinnerID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if players == nil {
+			if players, err = s.tournaments.GetPlayers(t.ID); err != nil {
+				return err
+			}
+		}
+		s.startGame(g, players)
+	}
+
+	return nil
+}
+
+// forfeitIdle засчитывает недоигранную к сроку партию сопернику того, чей сейчас ход.
+func (s *TournamentService) forfeitIdle(t *domain.Tournament, game *domain.Game) error {
+	loserID := ""
+	if idle := game.GetActivePlayer(); idle != nil {
+		loserID = idle.ID
+	}
+	log.Printf("Время партии %s турнира %s вышло, поражение засчитано %q", game.ID, t.ID, loserID)
+
+	return s.timeOut(t, game, loserID)
+}
+
+// timeOut завершает партию турнира, время которой вышло: loserID проигрывает, пустой loserID - ничья.
+// Игра закрывается в одной транзакции с записью результата в турнир, и оба игрока получают уведомление.
+// Если партию успели доиграть, её результат уже записан при завершении, и она не меняется.
+func (s *TournamentService) timeOut(t *domain.Tournament, game *domain.Game, loserID string) error {
+	if err := game.TimeOut(loserID); err != nil {
+		return err
+	}
+
+	recipients := []domain.TournamentPlayer{{UserID: game.Players[0].ID}, {UserID: game.Players[1].ID}}
+	err := s.games.Finish(game, func(map[string]*domain.User, domain.AwardFunc) (*domain.GameSettlement, error) {
+		notifications, err := tournamentNotifications(t, recipients, func(p domain.TournamentPlayer) string {
+			text := fmt.Sprintf("⏰ Время вышло, партия турнира «%s» завершена: ", t.Name)
+			switch {
+			case loserID == "":
+				return text + "засчитана ничья."
+			case p.UserID == loserID:
+				return text + "вы не сделали ход вовремя, засчитано поражение."
+			}
+			return text + "соперник не сделал ход вовремя, победа засчитана вам."
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &domain.GameSettlement{Notifications: notifications}, nil
+	})
+	if errors.Is(err, domain.ErrConcurrentUpdate) {
+		return nil
+	}
+	return err
+}
+
+func (s *TournamentService) archivedResult(g domain.TournamentGame) (domain.GameResult, bool, error) {
+	results, err := s.games.GetFinishedGamesByUser(g.FirstID)
+	if err != nil {
+		return domain.GameResult{}, false, err
+	}
+	for _, r := range results {
+		if r.GameID == g.GameID {
+			return r, true, nil
+		}
+	}
+	return domain.GameResult{}, false, nil
+}
+
+// ignoreRoundDone гасит конфликт, когда тот же шаг турнира уже выполнил параллельный вызов.
+func (s *TournamentService) ignoreRoundDone(err error) error {
+	if errors.Is(err, domain.ErrTournamentRoundDone) {
+		return nil
+	}
+	return err
+}
+
+func tournamentNotifications(
+	t *domain.Tournament,
+	recipients []domain.TournamentPlayer,
+	text func(domain.TournamentPlayer) string,
+) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	for _, p := range recipients {
+		notification, err := newNotification(dto.NewOutgoingMessage(
+			p.UserID,
+			text(p),
+			[]dto.Button{{Text: "📋 Турнир", Action: "/tournament " + t.ID}},
+		))
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func standingsText(t *domain.Tournament, players []domain.TournamentPlayer, games []domain.TournamentGame) string {
+	standings := domain.ComputeStandings(t.Format, players, games)
+
+	lines := []string{"Таблица:"}
+	for i, st := range standings {
+		line := fmt.Sprintf("%d. %s - %s (+%d =%d -%d)",
+			i+1, playerName(st.Player), formatScore(st.Score), st.Wins, st.Draws, st.Losses)
+		switch t.Format {
+		case domain.TournamentKnockout:
+			if st.Eliminated {
+				line += " ❌"
+			}
+		case domain.TournamentSwiss:
+			line += fmt.Sprintf(", Бх %g, З-Б %g", st.Buchholz, st.SonnebornBerger)
+		case domain.TournamentArena:
+			if st.Streak >= domain.ArenaStreakWins {
+				line += " 🔥"
+			}
+		default:
+			line += fmt.Sprintf(", З-Б %g", st.SonnebornBerger)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func roundText(players []domain.TournamentPlayer, games []domain.TournamentGame, round int) string {
+	names := playerNames(players)
+
+	var lines []string
+	for _, g := range games {
+		if g.Round != round {
+			continue
+		}
+		if g.IsBye() {
+			lines = append(lines, fmt.Sprintf("• %s - пропускает тур", names[g.FirstID]))
+			continue
+		}
+
+		result := "играют"
+		if g.Finished {
+			switch g.WinnerID {
+			case "":
+				result = "ничья"
+			default:
+				result = "победил(а) " + names[g.WinnerID]
+			}
+		}
+		lines = append(lines, fmt.Sprintf("• %s - %s: %s", names[g.FirstID], names[g.SecondID], result))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func pairingText(t *domain.Tournament, players []domain.TournamentPlayer, games []domain.TournamentGame, userID string) string {
+	names := playerNames(players)
+	for _, g := range games {
+		if !g.Involves(userID) {
+			continue
+		}
+		if g.IsBye() {
+			return fmt.Sprintf("Тур %d: у вас нет соперника, очко засчитано без игры.", t.Round)
+		}
+		symbol := "O"
+		if g.FirstID == userID {
+			symbol = "X"
+		}
+		text := fmt.Sprintf("ваш соперник - %s, вы играете за %s. Игра уже создана, доска придёт отдельным сообщением.",
+			names[g.Opponent(userID)], symbol)
+		if t.Format == domain.TournamentArena {
+			return "Новая партия: " + text
+		}
+		return fmt.Sprintf("Тур %d: %s", t.Round, text)
+	}
+
+	switch t.Format {
+	case domain.TournamentKnockout:
+		return "Вы выбыли из турнира, но можете следить за ним."
+	case domain.TournamentArena:
+		return "Ждём, пока освободится соперник."
+	}
+	return fmt.Sprintf("Тур %d: ждём остальных участников.", t.Round)
+}
+
+func arenaGamesText(players []domain.TournamentPlayer, games []domain.TournamentGame) string {
+	names := playerNames(players)
+
+	var lines []string
+	for _, g := range games {
+		if !g.Finished && !g.IsBye() {
+			lines = append(lines, fmt.Sprintf("• %s - %s", names[g.FirstID], names[g.SecondID]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runningState(t *domain.Tournament) string {
+	if t.Format == domain.TournamentArena {
+		if left := time.Until(t.EndsAt()); left > 0 {
+			return fmt.Sprintf("до конца %s", formatTTL(left))
+		}
+		return "подводятся итоги"
+	}
+	return fmt.Sprintf("идёт тур %d из %d", t.Round, t.Rounds)
+}
+
+func showState(t *domain.Tournament) string {
+	if t.Format == domain.TournamentArena {
+		if left := time.Until(t.EndsAt()); left > 0 {
+			return fmt.Sprintf("До конца %s.", formatTTL(left))
+		}
+		return "Время вышло, подводятся итоги."
+	}
+	return fmt.Sprintf("Тур %d из %d.", t.Round, t.Rounds)
+}
+
+func pairedPlayers(players []domain.TournamentPlayer, pairs [][2]string) []domain.TournamentPlayer {
+	involved := map[string]bool{}
+	for _, pair := range pairs {
+		involved[pair[0]] = true
+		involved[pair[1]] = true
+	}
+
+	var result []domain.TournamentPlayer
+	for _, p := range players {
+		if involved[p.UserID] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func playerNames(players []domain.TournamentPlayer) map[string]string {
+	names := make(map[string]string, len(players))
+	for _, p := range players {
+		names[p.UserID] = playerName(p)
+	}
+	return names
+}
+
+func playerName(p domain.TournamentPlayer) string {
+	if p.UserName != "" {
+		return p.UserName
+	}
+	return getUserDisplayName(p.UserID)
+}
+
+func gameWinnerID(game *domain.Game) string {
+	if winner := game.Winner(); winner != nil {
+		return winner.ID
+	}
+	return ""
+}
+
+func formatName(format domain.TournamentFormat) string {
+	switch format {
+	case domain.TournamentKnockout:
+		return "на выбывание"
+	case domain.TournamentSwiss:
+		return "швейцарская система"
+	case domain.TournamentArena:
+		return "арена"
+	}
+	return "круговой"
+}
+
+// formatScore печатает очки с половинками: 2½.
+func formatScore(score float64) string {
+	whole := int(score)
+	rest := score - float64(whole)
+
+	text := strconv.Itoa(whole)
+	switch {
+	case rest >= 0.75:
+		text = strconv.Itoa(whole + 1)
+	case rest >= 0.25:
+		if whole == 0 {
+			text = "½"
+		} else {
+			text += "½"
+		}
+	}
+	return text
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+type UserService struct {
+	users domain.UserRepository
+}
+
+func NewUserService(users domain.UserRepository) *UserService {
+	return &UserService{users: users}
+}
+
+// Touch создаёт профиль при первом обращении пользователя и обновляет его при последующих.
+func (s *UserService) Touch(req dto.TouchUserRequest) (*domain.User, error) {
+	user := &domain.User{
+		ID:          req.UserID,
+		DisplayName: req.DisplayName,
+		Username:    strings.TrimPrefix(req.Username, "@"),
+		Language:    req.Language,
+		LastSeenAt:  time.Now(),
+	}
+
+	if err := s.users.Upsert(user); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения профиля: %w", err)
+	}
+
+	return user, nil
+}
+
+// findUser ищет профиль по @username или по идентификатору пользователя, который используют кнопки.
+// В кнопках старых сообщений идентификатор может быть ещё в формате chat_<id>.
+func findUser(users domain.UserRepository, target string) (*domain.User, error) {
+	if strings.HasPrefix(target, "@") {
+		return users.GetByUsername(strings.TrimPrefix(target, "@"))
+	}
+
+	user, err := users.GetByID(domain.NormalizeUserID(target))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return users.GetByUsername(target)
+	}
+	return user, err
+}
+
+func profileName(user *domain.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return getUserDisplayName(user.ID)
+}
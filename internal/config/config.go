@@ -2,34 +2,140 @@ package config
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "modernc.org/sqlite"
+)
+
+// defaultArchiveBatchSize - размер пачки архивации, если ARCHIVE_BATCH_SIZE не задан или неверен.
+const defaultArchiveBatchSize = 500
+
+const (
+	StoragePostgres   = "postgres"
+	StorageEventStore = "eventstore"
+	StorageSQLite     = "sqlite"
 )
 
 type AppConfig struct {
-	ServiceName string
-	Version     string
-	Environment string
-	Port        string
-	DatabaseURL string
+	ServiceName           string
+	Version               string
+	Environment           string
+	Port                  string
+	StorageDriver         string
+	DatabaseURL           string
+	SQLitePath            string
+	EventSnapshotInterval int
+	ArchiveAfter          time.Duration
+	ArchiveInterval       time.Duration
+	ArchiveBatchSize      int
+	AdminIDs              []string
+	NotifyURL             string
+	NotifySecret          string
+	NotifyInterval        time.Duration
+	NotifyBatchSize       int
+	NotifyMaxAttempts     int
+	MatchmakingInterval   time.Duration
+	ChallengeTTL          time.Duration
+	TournamentInterval    time.Duration
+	TournamentRoundTime   time.Duration
+	SeasonLength          time.Duration
+	SeasonInterval        time.Duration
 }
 
 func New() *AppConfig {
-	return &AppConfig{
-		ServiceName: os.Getenv("SERVICE_NAME"),
-		Version:     os.Getenv("VERSION"),
-		Environment: os.Getenv("ENVIRONMENT"),
-		Port:        os.Getenv("PORT"),
-		DatabaseURL: os.Getenv("DATABASE_URL"),
+	cfg := &AppConfig{
+		ServiceName:           os.Getenv("SERVICE_NAME"),
+		Version:               os.Getenv("VERSION"),
+		Environment:           os.Getenv("ENVIRONMENT"),
+		Port:                  os.Getenv("PORT"),
+		StorageDriver:         os.Getenv("STORAGE_DRIVER"),
+		DatabaseURL:           os.Getenv("DATABASE_URL"),
+		SQLitePath:            os.Getenv("SQLITE_PATH"),
+		EventSnapshotInterval: getEnvInt("EVENT_SNAPSHOT_INTERVAL", 10),
+		ArchiveAfter:          getEnvDuration("ARCHIVE_AFTER", 0),
+		ArchiveInterval:       getEnvDuration("ARCHIVE_INTERVAL", time.Hour),
+		ArchiveBatchSize:      getEnvInt("ARCHIVE_BATCH_SIZE", defaultArchiveBatchSize),
+		AdminIDs:              getEnvList("ADMIN_IDS"),
+		NotifyURL:             os.Getenv("NOTIFY_URL"),
+		NotifySecret:          os.Getenv("NOTIFY_SECRET"),
+		NotifyInterval:        getEnvDuration("NOTIFY_INTERVAL", 2*time.Second),
+		NotifyBatchSize:       getEnvInt("NOTIFY_BATCH_SIZE", 100),
+		NotifyMaxAttempts:     getEnvInt("NOTIFY_MAX_ATTEMPTS", 10),
+		MatchmakingInterval:   getEnvDuration("MATCHMAKING_INTERVAL", 5*time.Second),
+		ChallengeTTL:          getEnvDuration("CHALLENGE_TTL", 10*time.Minute),
+		TournamentInterval:    getEnvDuration("TOURNAMENT_INTERVAL", 10*time.Second),
+		TournamentRoundTime:   getEnvDuration("TOURNAMENT_ROUND_TIME", 30*time.Minute),
+		SeasonLength:          getEnvDuration("SEASON_LENGTH", 30*24*time.Hour),
+		SeasonInterval:        getEnvDuration("SEASON_INTERVAL", time.Minute),
+	}
+
+	if cfg.StorageDriver == "" {
+		cfg.StorageDriver = StoragePostgres
+	}
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "tictactoe.db"
+	}
+	if cfg.ArchiveBatchSize <= 0 {
+		log.Printf("ARCHIVE_BATCH_SIZE должен быть больше нуля, используется %d", defaultArchiveBatchSize)
+		cfg.ArchiveBatchSize = defaultArchiveBatchSize
 	}
+
+	return cfg
 }
 
-func (c *AppConfig) ConnectDB() *pgx.Conn {
-	conn, err := pgx.Connect(context.Background(), c.DatabaseURL)
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (c *AppConfig) ConnectDB() *pgxpool.Pool {
+	pool, err := pgxpool.New(context.Background(), c.DatabaseURL)
 	if err != nil {
 		log.Fatalf("Ошибка подключения к базе данных: %v", err)
 	}
-	return conn
+	return pool
+}
+
+func (c *AppConfig) ConnectSQLite() *sql.DB {
+	dsn := "file:" + c.SQLitePath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		log.Fatalf("Ошибка открытия базы SQLite: %v", err)
+	}
+
+	// SQLite допускает только одного писателя, поэтому все запросы идут через одно соединение
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Ошибка подключения к базе SQLite: %v", err)
+	}
+	return db
 }
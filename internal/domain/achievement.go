@@ -0,0 +1,145 @@
+package domain
+
+import "time"
+
+type AchievementCode string
+
+const (
+	AchievementFirstWin    AchievementCode = "first_win"
+	AchievementWinStreak10 AchievementCode = "win_streak_10"
+	AchievementForkWin     AchievementCode = "fork_win"
+	AchievementGames100    AchievementCode = "games_100"
+)
+
+type Achievement struct {
+	Code        AchievementCode
+	Icon        string
+	Title       string
+	Description string
+}
+
+// Achievements - каталог наград в порядке показа в профиле.
+var Achievements = []Achievement{
+	{AchievementFirstWin, "🥇", "Первая победа", "выиграть первую игру"},
+	{AchievementForkWin, "🍴", "Вилка", "победить вилкой - двумя угрозами сразу"},
+	{AchievementWinStreak10, "🔥", "Непобедимый", "выиграть 10 игр подряд"},
+	{AchievementGames100, "💯", "Ветеран", "сыграть 100 игр"},
+}
+
+type UserAchievement struct {
+	UserID    string
+	Code      AchievementCode
+	GameID    string
+	AwardedAt time.Time
+}
+
+type AchievementRepository interface {
+	GetByUser(userID string) ([]UserAchievement, error)
+	GetByGame(gameID string) ([]UserAchievement, error)
+}
+
+func AchievementByCode(code AchievementCode) (Achievement, bool) {
+	for _, a := range Achievements {
+		if a.Code == code {
+			return a, true
+		}
+	}
+	return Achievement{}, false
+}
+
+// EvaluateAchievements возвращает награды, условия которых выполнены после завершения игры.
+// stats должна уже учитывать эту игру.
+func EvaluateAchievements(game *Game, userID string, stats PlayerStats) []AchievementCode {
+	var codes []AchievementCode
+
+	winner := game.Winner()
+	won := winner != nil && winner.ID == userID
+
+	if stats.Wins >= 1 {
+		codes = append(codes, AchievementFirstWin)
+	}
+	if won && game.WonWithFork() {
+		codes = append(codes, AchievementForkWin)
+	}
+	if stats.BestStreak >= 10 {
+		codes = append(codes, AchievementWinStreak10)
+	}
+	if stats.Games >= 100 {
+		codes = append(codes, AchievementGames100)
+	}
+
+	return codes
+}
+
+// WonWithFork проверяет, что победа пришла с вилки: предпоследний ход победителя создал сразу
+// две открытые угрозы - линии с двумя его фишками и пустой клеткой, - которые не закрыть одним ходом.
+// Позиция восстанавливается по Moves; если порядок ходов сохранён не полностью, вилка не засчитывается.
+func (g *Game) WonWithFork() bool {
+	winner := g.Winner()
+	if winner == nil {
+		return false
+	}
+
+	filled := 0
+	for _, row := range g.Board {
+		for _, cell := range row {
+			if cell != "" {
+				filled++
+			}
+		}
+	}
+	if filled != len(g.Moves) {
+		return false
+	}
+
+	// ходы чередуются с крестиков, поэтому ходы победителя - каждый второй
+	first := 0
+	if winner.Symbol != "X" {
+		first = 1
+	}
+	var own []int
+	for i := first; i < len(g.Moves); i += 2 {
+		own = append(own, i)
+	}
+	if len(own) < 2 {
+		return false
+	}
+
+	var board [3][3]string
+	for i, c := range g.Moves[:own[len(own)-2]+1] {
+		symbol := "X"
+		if i%2 != 0 {
+			symbol = "O"
+		}
+		board[c.Row][c.Column] = symbol
+	}
+
+	threats := map[Coordinate]bool{}
+	for _, line := range winningLines {
+		mine, empty := 0, []Coordinate(nil)
+		for _, c := range line {
+			switch board[c.Row][c.Column] {
+			case winner.Symbol:
+				mine++
+			case "":
+				empty = append(empty, c)
+			}
+		}
+		if mine == 2 && len(empty) == 1 {
+			threats[empty[0]] = true
+		}
+	}
+
+	return len(threats) >= 2
+}
+
+var winningLines = [][3]Coordinate{
+	{{0, 0}, {0, 1}, {0, 2}},
+	{{1, 0}, {1, 1}, {1, 2}},
+	{{2, 0}, {2, 1}, {2, 2}},
+	{{0, 0}, {1, 0}, {2, 0}},
+	{{0, 1}, {1, 1}, {2, 1}},
+	{{0, 2}, {1, 2}, {2, 2}},
+	{{0, 0}, {1, 1}, {2, 2}},
+	{{0, 2}, {1, 1}, {2, 0}},
+}
@@ -0,0 +1,20 @@
+package domain
+
+type BlockRepository interface {
+	Block(userID, blockedID string) error
+	Unblock(userID, blockedID string) (bool, error)
+	// IsBlocked сообщает, заблокировал ли кто-то из двух пользователей другого.
+	IsBlocked(userID, otherID string) (bool, error)
+	ListBlocked(userID string) ([]string, error)
+}
+
+// Blocks - множество блокировок между пользователями, нужно для подбора пар без обращения к базе на каждую пару.
+type Blocks map[[2]string]bool
+
+func (b Blocks) Add(userID, blockedID string) {
+	b[[2]string{userID, blockedID}] = true
+}
+
+func (b Blocks) Between(userID, otherID string) bool {
+	return b[[2]string{userID, otherID}] || b[[2]string{otherID, userID}]
+}
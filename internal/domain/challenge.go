@@ -0,0 +1,82 @@
+package domain
+
+import "time"
+
+type ChallengeStatus string
+
+const (
+	ChallengeStatusPending  ChallengeStatus = "pending"
+	ChallengeStatusAccepted ChallengeStatus = "accepted"
+	ChallengeStatusDeclined ChallengeStatus = "declined"
+	ChallengeStatusExpired  ChallengeStatus = "expired"
+)
+
+// Challenge - приглашение конкретного игрока в игру. После принятия GameID указывает на созданную игру.
+type Challenge struct {
+	ID             string
+	ChallengerID   string
+	ChallengerName string
+	TargetID       string
+	TargetName     string
+	Status         ChallengeStatus
+	GameID         string
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type ChallengeRepository interface {
+	Create(challenge *Challenge, notifications ...Notification) error
+	GetByID(id string) (*Challenge, error)
+	// Resolve сохраняет ответ на вызов, только если он всё ещё ожидает ответа, иначе возвращает ErrChallengeClosed.
+	Resolve(challenge *Challenge, notifications ...Notification) error
+}
+
+func NewChallenge(challengerID, challengerName, targetID, targetName string, ttl time.Duration) (*Challenge, error) {
+	if challengerID == targetID {
+		return nil, ErrChallengeSelf
+	}
+
+	now := time.Now()
+	return &Challenge{
+		ChallengerID:   challengerID,
+		ChallengerName: challengerName,
+		TargetID:       targetID,
+		TargetName:     targetName,
+		Status:         ChallengeStatusPending,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(ttl),
+	}, nil
+}
+
+func (c *Challenge) Accept(userID, gameID string, now time.Time) error {
+	if err := c.checkAnswer(userID, now); err != nil {
+		return err
+	}
+
+	c.Status = ChallengeStatusAccepted
+	c.GameID = gameID
+	return nil
+}
+
+func (c *Challenge) Decline(userID string, now time.Time) error {
+	if err := c.checkAnswer(userID, now); err != nil {
+		return err
+	}
+
+	c.Status = ChallengeStatusDeclined
+	return nil
+}
+
+func (c *Challenge) checkAnswer(userID string, now time.Time) error {
+	if c.TargetID != userID {
+		return ErrForbidden
+	}
+	if c.Status != ChallengeStatusPending {
+		return ErrChallengeClosed
+	}
+	if now.After(c.ExpiresAt) {
+		c.Status = ChallengeStatusExpired
+		return ErrChallengeExpired
+	}
+	return nil
+}
@@ -7,10 +7,40 @@ var (
 	ErrGameNotFound  = errors.New("игра не найдена")
 	ErrGameFinished  = errors.New("игра завершена")
 
-	ErrNotPlayerTurn = errors.New("сейчас не ваш ход")
-	ErrAlreadyInGame = errors.New("вы уже в игре")
-	ErrCannotJoin    = errors.New("к этой игре нельзя присоединиться")
+	ErrConcurrentUpdate   = errors.New("игра была изменена другим запросом, попробуйте ещё раз")
+	ErrInvalidEventStream = errors.New("поток событий игры повреждён")
+
+	ErrNotPlayerTurn  = errors.New("сейчас не ваш ход")
+	ErrAlreadyInGame  = errors.New("вы уже в игре")
+	ErrCannotJoin     = errors.New("к этой игре нельзя присоединиться")
+	ErrNotParticipant = errors.New("вы не являетесь участником этой игры")
 
 	ErrInvalidMove       = errors.New("недопустимый ход")
 	ErrInvalidCoordinate = errors.New("неверные координаты")
+
+	ErrForbidden = errors.New("недостаточно прав")
+
+	ErrUserNotFound = errors.New("пользователь не найден")
+
+	ErrChallengeNotFound = errors.New("вызов не найден")
+	ErrChallengeSelf     = errors.New("нельзя вызвать на игру самого себя")
+	ErrChallengeExpired  = errors.New("срок действия вызова истёк")
+	ErrChallengeClosed   = errors.New("на вызов уже ответили")
+
+	ErrFriendSelf  = errors.New("нельзя добавить в друзья самого себя")
+	ErrBlockSelf   = errors.New("нельзя заблокировать самого себя")
+	ErrUserBlocked = errors.New("вы не можете играть с этим пользователем")
+
+	ErrMatchTaken = errors.New("игрок из пары уже покинул очередь")
+
+	ErrTournamentNotFound  = errors.New("турнир не найден")
+	ErrInvalidTournament   = errors.New("неверные параметры турнира")
+	ErrRegistrationClosed  = errors.New("регистрация на турнир закрыта")
+	ErrTournamentFull      = errors.New("в турнире нет свободных мест")
+	ErrAlreadyRegistered   = errors.New("вы уже зарегистрированы в турнире")
+	ErrNotRegistered       = errors.New("вы не зарегистрированы в турнире")
+	ErrTournamentRoundDone = errors.New("тур уже начат")
+
+	ErrSeasonNotFound = errors.New("сезон не найден")
+	ErrSeasonClosed   = errors.New("сезон уже завершён")
 )
@@ -0,0 +1,15 @@
+package domain
+
+// Friend - игрок из списка друзей. GameStatus - статус его текущей игры
+// (active или waiting) либо пустая строка, если он сейчас не играет.
+type Friend struct {
+	UserID      string
+	DisplayName string
+	GameStatus  GameStatus
+}
+
+type FriendRepository interface {
+	Add(userID, friendID string) error
+	Remove(userID, friendID string) (bool, error)
+	List(userID string) ([]Friend, error)
+}
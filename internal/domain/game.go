@@ -11,13 +11,27 @@ type Player struct {
 	IsActive bool
 }
 
+// Game - партия. ChatID - групповой чат, в котором игра создана и где живёт её общая доска;
+// у игр из личных чатов он пустой. InlineMessageID - сообщение, отправленное через inline-режим бота
+// в любой чат: доска игры живёт в нём, а после окончания игры в том же сообщении можно начать новую.
+// Moves - клетки ходов по порядку, первый ход за крестиками. У игр, начатых до того, как порядок
+// ходов начал сохраняться, Moves пуст или короче числа фишек на доске.
+// TimedOutBy - игрок, не сделавший ход вовремя: партия без доигрывания засчитана его сопернику.
 type Game struct {
-	ID        string
-	Board     [3][3]string
-	Players   [2]Player
-	Status    GameStatus
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              string
+	Board           [3][3]string
+	Players         [2]Player
+	Status          GameStatus
+	Rated           bool
+	ChatID          string
+	InlineMessageID string
+	Moves           []Coordinate
+	TimedOutBy      string
+	Version         int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	events []GameEvent
 }
 
 type GameStatus string
@@ -33,16 +47,32 @@ type Coordinate struct {
 	Column int
 }
 
-func NewGame(creatorID, creatorName string) *Game {
-	return &Game{
-		Status:    GameStatusWaiting,
-		Players:   [2]Player{{ID: creatorID, Name: creatorName, IsActive: false}},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+func NewGame(creatorID, creatorName string, rated bool, chatID, inlineMessageID string) *Game {
+	now := time.Now()
+	game := &Game{
+		Status:          GameStatusWaiting,
+		Players:         [2]Player{{ID: creatorID, Name: creatorName, IsActive: false}},
+		Rated:           rated,
+		ChatID:          chatID,
+		InlineMessageID: inlineMessageID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
+	game.record(GameEvent{Type: GameEventCreated, PlayerID: creatorID, PlayerName: creatorName, Rated: rated, ChatID: chatID, InlineMessageID: inlineMessageID, OccurredAt: now})
+	return game
+}
+
+// IsPublic сообщает, что у игры одна общая доска (в групповом чате или inline-сообщении),
+// а не отдельная доска в личном чате каждого игрока.
+func (g *Game) IsPublic() bool {
+	return g.ChatID != "" || g.InlineMessageID != ""
 }
 
 func (g *Game) MakeMove(playerID string, coord Coordinate) error {
+	return g.move(playerID, coord, time.Now())
+}
+
+func (g *Game) move(playerID string, coord Coordinate, at time.Time) error {
 	if g.Status != GameStatusActive {
 		return ErrGameNotActive
 	}
@@ -64,18 +94,21 @@ func (g *Game) MakeMove(playerID string, coord Coordinate) error {
 	}
 
 	g.Board[coord.Row][coord.Column] = player.Symbol
-	g.UpdatedAt = time.Now()
+	g.Moves = append(g.Moves, coord)
+	g.UpdatedAt = at
+	g.record(GameEvent{Type: GameEventMoved, PlayerID: playerID, Symbol: player.Symbol, Coordinate: coord, OccurredAt: at})
 
-	gameWon := false
+	winner := ""
 	for _, p := range g.Players {
 		if g.CheckWin(p.Symbol) {
-			gameWon = true
+			winner = p.Symbol
 			break
 		}
 	}
 
-	if gameWon || g.isBoardFull() {
+	if winner != "" || g.isBoardFull() {
 		g.Status = GameStatusFinished
+		g.record(GameEvent{Type: GameEventFinished, Symbol: winner, OccurredAt: at})
 	} else {
 		g.Players[0].IsActive = !g.Players[0].IsActive
 		g.Players[1].IsActive = !g.Players[1].IsActive
@@ -84,7 +117,44 @@ func (g *Game) MakeMove(playerID string, coord Coordinate) error {
 	return nil
 }
 
+// TimeOut завершает активную игру, время на которую вышло: playerID проигрывает,
+// а пустой playerID означает, что партия засчитана ничьей.
+func (g *Game) TimeOut(playerID string) error {
+	return g.timeOut(playerID, time.Now())
+}
+
+func (g *Game) timeOut(playerID string, at time.Time) error {
+	if g.Status != GameStatusActive {
+		return ErrGameNotActive
+	}
+	if playerID != "" && g.Players[0].ID != playerID && g.Players[1].ID != playerID {
+		return ErrNotParticipant
+	}
+
+	g.Status = GameStatusFinished
+	g.TimedOutBy = playerID
+	g.UpdatedAt = at
+	g.record(GameEvent{Type: GameEventTimedOut, PlayerID: playerID, OccurredAt: at})
+	return nil
+}
+
 func (g *Game) JoinGame(playerID, playerName string) error {
+	symbol := "O"
+	if time.Now().UnixNano()%2 != 0 {
+		symbol = "X"
+	}
+	return g.join(playerID, playerName, symbol, time.Now())
+}
+
+// JoinGameAs присоединяет игрока с заданным символом, например когда очерёдность определяет турнир.
+func (g *Game) JoinGameAs(playerID, playerName, symbol string) error {
+	if symbol != "X" && symbol != "O" {
+		return ErrCannotJoin
+	}
+	return g.join(playerID, playerName, symbol, time.Now())
+}
+
+func (g *Game) join(playerID, playerName, symbol string, at time.Time) error {
 	if g.Status != GameStatusWaiting {
 		return ErrCannotJoin
 	}
@@ -96,18 +166,12 @@ func (g *Game) JoinGame(playerID, playerName string) error {
 	g.Players[1] = Player{ID: playerID, Name: playerName}
 	g.Status = GameStatusActive
 
-	if time.Now().UnixNano()%2 == 0 {
-		g.Players[0].Symbol = "X"
-		g.Players[1].Symbol = "O"
-		g.Players[0].IsActive = true
-		g.Players[1].IsActive = false
-	} else {
-		g.Players[0].Symbol = "O"
-		g.Players[1].Symbol = "X"
-		g.Players[0].IsActive = false
-		g.Players[1].IsActive = true
-	}
+	g.Players[1].Symbol = symbol
+	g.Players[0].Symbol = getOpponentSymbol(symbol)
+	g.Players[0].IsActive = g.Players[0].Symbol == "X"
+	g.Players[1].IsActive = g.Players[1].Symbol == "X"
 
+	g.record(GameEvent{Type: GameEventJoined, PlayerID: playerID, PlayerName: playerName, Symbol: symbol, OccurredAt: at})
 	return nil
 }
 
@@ -150,6 +214,25 @@ func (g *Game) isBoardFull() bool {
 	return true
 }
 
+func (g *Game) Winner() *Player {
+	if g.Status != GameStatusFinished {
+		return nil
+	}
+	if g.TimedOutBy != "" {
+		for i := range g.Players {
+			if g.Players[i].ID != g.TimedOutBy {
+				return &g.Players[i]
+			}
+		}
+	}
+	for i := range g.Players {
+		if g.Players[i].Symbol != "" && g.CheckWin(g.Players[i].Symbol) {
+			return &g.Players[i]
+		}
+	}
+	return nil
+}
+
 func (g *Game) GetActivePlayer() *Player {
 	for i := range g.Players {
 		if g.Players[i].IsActive {
@@ -158,3 +241,10 @@ func (g *Game) GetActivePlayer() *Player {
 	}
 	return nil
 }
+
+func getOpponentSymbol(symbol string) string {
+	if symbol == "X" {
+		return "O"
+	}
+	return "X"
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type GameEventType string
+
+const (
+	GameEventCreated  GameEventType = "created"
+	GameEventJoined   GameEventType = "joined"
+	GameEventMoved    GameEventType = "moved"
+	GameEventFinished GameEventType = "finished"
+	GameEventTimedOut GameEventType = "timed_out"
+)
+
+// GameEvent описывает одно изменение игры. Sequence и Version заполняет хранилище:
+// Sequence - номер события в потоке игры, Version - версия игры после сохранения.
+type GameEvent struct {
+	Sequence        int
+	Version         int
+	Type            GameEventType
+	PlayerID        string
+	PlayerName      string
+	Symbol          string
+	Rated           bool
+	ChatID          string
+	InlineMessageID string
+	Coordinate      Coordinate
+	OccurredAt      time.Time
+}
+
+type GameEventStore interface {
+	GetEvents(gameID string) ([]GameEvent, error)
+}
+
+func (g *Game) record(event GameEvent) {
+	g.events = append(g.events, event)
+}
+
+func (g *Game) PendingEvents() []GameEvent {
+	return g.events
+}
+
+func (g *Game) ClearPendingEvents() {
+	g.events = nil
+}
+
+// RebuildGame восстанавливает состояние игры из снимка (может быть nil) и событий после него.
+// Итог игры пересчитывается текущими правилами, событие finished служит только для аудита.
+func RebuildGame(id string, snapshot *Game, events []GameEvent) (*Game, error) {
+	game := &Game{ID: id}
+	if snapshot != nil {
+		copied := *snapshot
+		copied.events = nil
+		game = &copied
+	}
+
+	for _, event := range events {
+		if err := game.apply(event); err != nil {
+			return nil, err
+		}
+	}
+
+	game.ClearPendingEvents()
+	return game, nil
+}
+
+func (g *Game) apply(event GameEvent) error {
+	var err error
+
+	switch event.Type {
+	case GameEventCreated:
+		if g.Status != "" {
+			return ErrInvalidEventStream
+		}
+		g.Status = GameStatusWaiting
+		g.Players[0] = Player{ID: event.PlayerID, Name: event.PlayerName}
+		g.Rated = event.Rated
+		g.ChatID = event.ChatID
+		g.InlineMessageID = event.InlineMessageID
+		g.CreatedAt = event.OccurredAt
+		g.UpdatedAt = event.OccurredAt
+
+	case GameEventJoined:
+		err = g.join(event.PlayerID, event.PlayerName, event.Symbol, event.OccurredAt)
+
+	case GameEventMoved:
+		// если новые правила завершили игру раньше, оставшиеся ходы уже ничего не меняют
+		if g.Status == GameStatusFinished {
+			break
+		}
+		err = g.move(event.PlayerID, event.Coordinate, event.OccurredAt)
+
+	case GameEventFinished:
+
+	case GameEventTimedOut:
+		if g.Status == GameStatusFinished {
+			break
+		}
+		err = g.timeOut(event.PlayerID, event.OccurredAt)
+
+	default:
+		return ErrInvalidEventStream
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: событие %d: %v", ErrInvalidEventStream, event.Sequence, err)
+	}
+
+	g.Version = event.Version
+	return nil
+}
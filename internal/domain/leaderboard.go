@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+type LeaderboardMetric string
+
+const (
+	LeaderboardByRating LeaderboardMetric = "rating"
+	LeaderboardByWins   LeaderboardMetric = "wins"
+)
+
+type LeaderboardPeriod string
+
+const (
+	PeriodAllTime LeaderboardPeriod = "all"
+	PeriodMonth   LeaderboardPeriod = "month"
+	PeriodWeek    LeaderboardPeriod = "week"
+	// PeriodSeason - текущий сезон; его начало хранится в SeasonRepository, а не вычисляется по дате.
+	PeriodSeason LeaderboardPeriod = "season"
+)
+
+// LeaderboardFilter задаёт таблицу лидеров. Since ограничивает учитываемые игры (нулевое значение - за всё время),
+// ChatID оставляет только участников, игравших в этом групповом чате.
+type LeaderboardFilter struct {
+	Metric LeaderboardMetric
+	Since  time.Time
+	ChatID string
+	Limit  int
+}
+
+type LeaderboardEntry struct {
+	UserID      string
+	DisplayName string
+	Rating      int
+	Wins        int
+}
+
+type LeaderboardRepository interface {
+	GetLeaderboard(filter LeaderboardFilter) ([]LeaderboardEntry, error)
+	// AddChatMember отмечает, что пользователь играл в групповом чате.
+	AddChatMember(chatID, userID string) error
+}
+
+// Since возвращает начало периода по UTC: понедельник текущей недели или первое число месяца.
+func (p LeaderboardPeriod) Since(now time.Time) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch p {
+	case PeriodMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case PeriodWeek:
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// Допустимая разница рейтингов начинается с MatchBaseRange и растёт на MatchRangeStep
+	// за каждые MatchWidenInterval ожидания, но не больше MatchMaxRange.
+	MatchBaseRange     = 50
+	MatchRangeStep     = 50
+	MatchWidenInterval = 10 * time.Second
+	MatchMaxRange      = 500
+)
+
+type QueueEntry struct {
+	UserID     string
+	UserName   string
+	Rating     int
+	EnqueuedAt time.Time
+}
+
+type Match struct {
+	First  QueueEntry
+	Second QueueEntry
+}
+
+type MatchmakingRepository interface {
+	// Enqueue ставит игрока в очередь; повторная постановка обновляет имя и рейтинг, но сохраняет время ожидания.
+	Enqueue(entry QueueEntry) error
+	Remove(userID string) (bool, error)
+	// FindMatches подбирает пары по PairQueue с учётом блокировок. Из очереди игроки уходят
+	// вместе с созданием игры для пары, см. GameOrigin.
+	FindMatches(now time.Time) ([]Match, error)
+}
+
+func (e QueueEntry) RatingRange(now time.Time) int {
+	waited := now.Sub(e.EnqueuedAt)
+	if waited < 0 {
+		waited = 0
+	}
+
+	r := MatchBaseRange + MatchRangeStep*int(waited/MatchWidenInterval)
+	if r > MatchMaxRange {
+		return MatchMaxRange
+	}
+	return r
+}
+
+// PairQueue жадно подбирает пары: дольше всех ожидающий игрок получает ближайшего по рейтингу соперника,
+// если разница укладывается в диапазон хотя бы одного из них и между ними нет блокировки.
+func PairQueue(entries []QueueEntry, now time.Time, blocks Blocks) []Match {
+	sorted := make([]QueueEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EnqueuedAt.Before(sorted[j].EnqueuedAt)
+	})
+
+	paired := make([]bool, len(sorted))
+	var matches []Match
+
+	for i := range sorted {
+		if paired[i] {
+			continue
+		}
+
+		best, bestDiff := -1, 0
+		for j := i + 1; j < len(sorted); j++ {
+			if paired[j] || blocks.Between(sorted[i].UserID, sorted[j].UserID) {
+				continue
+			}
+
+			diff := abs(sorted[i].Rating - sorted[j].Rating)
+			allowed := max(sorted[i].RatingRange(now), sorted[j].RatingRange(now))
+			if diff <= allowed && (best < 0 || diff < bestDiff) {
+				best, bestDiff = j, diff
+			}
+		}
+
+		if best >= 0 {
+			paired[i], paired[best] = true, true
+			matches = append(matches, Match{First: sorted[i], Second: sorted[best]})
+		}
+	}
+
+	return matches
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
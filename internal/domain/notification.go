@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Notification - запись исходящего уведомления (outbox), сохраняется вместе с изменением игры.
+type Notification struct {
+	ID        string
+	UserID    string
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type NotificationRepository interface {
+	FetchPending(limit int, lease time.Duration) ([]Notification, error)
+	MarkSent(id string) error
+	MarkFailed(id string, nextAttemptAt time.Time, lastError string) error
+	MarkDead(id string, lastError string) error
+}
+
+type NotificationSender interface {
+	Send(notification Notification) error
+}
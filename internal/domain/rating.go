@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	DefaultRating = 1200
+
+	// ProvisionalGames - сколько рейтинговых игр новый игрок считается провизорным
+	// и получает повышенный коэффициент K, чтобы быстрее прийти к своему рейтингу.
+	ProvisionalGames = 20
+	ProvisionalK     = 40
+	EstablishedK     = 20
+)
+
+type RatingChange struct {
+	UserID    string
+	GameID    string
+	Before    int
+	After     int
+	Delta     int
+	CreatedAt time.Time
+}
+
+type RatingRepository interface {
+	GetChangesByGame(gameID string) ([]RatingChange, error)
+	GetHistory(userID string, limit int) ([]RatingChange, error)
+}
+
+// CalculateRatingChanges считает изменения рейтинга по Эло для завершённой рейтинговой игры.
+// Игроки без профиля считаются новичками с рейтингом DefaultRating.
+func CalculateRatingChanges(game *Game, users map[string]*User) []RatingChange {
+	if !game.Rated || game.Status != GameStatusFinished {
+		return nil
+	}
+
+	var ratings [2]int
+	var kFactors [2]float64
+	for i, p := range game.Players {
+		ratings[i] = DefaultRating
+		kFactors[i] = ProvisionalK
+		if user, ok := users[p.ID]; ok {
+			ratings[i] = user.Rating
+			if user.RatedGames >= ProvisionalGames {
+				kFactors[i] = EstablishedK
+			}
+		}
+	}
+
+	scores := [2]float64{0.5, 0.5}
+	if winner := game.Winner(); winner != nil {
+		scores = [2]float64{0, 0}
+		for i, p := range game.Players {
+			if p.ID == winner.ID {
+				scores[i] = 1
+			}
+		}
+	}
+
+	now := time.Now()
+	changes := make([]RatingChange, 0, 2)
+	for i, p := range game.Players {
+		opponent := ratings[1-i]
+		expected := 1 / (1 + math.Pow(10, float64(opponent-ratings[i])/400))
+		delta := int(math.Round(kFactors[i] * (scores[i] - expected)))
+
+		changes = append(changes, RatingChange{
+			UserID:    p.ID,
+			GameID:    game.ID,
+			Before:    ratings[i],
+			After:     ratings[i] + delta,
+			Delta:     delta,
+			CreatedAt: now,
+		})
+	}
+
+	return changes
+}
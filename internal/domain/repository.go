@@ -1,9 +1,72 @@
 package domain
 
+import "time"
+
+// GameRepository хранит игры. Create, Start и Join убирают игроков игры из очереди быстрой игры
+// в той же транзакции, иначе подбор пар создал бы им вторую игру.
 type GameRepository interface {
 	Create(game *Game) error
-	Update(game *Game) error
+	// Start сохраняет игру, уже начатую двумя игроками, вместе с уведомлениями и закрывает origin
+	// в той же транзакции. Если origin закрыть не удалось, игра не создаётся.
+	Start(game *Game, origin GameOrigin, notifications ...Notification) error
+	// Join сохраняет игру, к которой присоединился второй игрок, вместе с уведомлениями.
+	Join(game *Game, notifications ...Notification) error
+	Update(game *Game, notifications ...Notification) error
+	// Finish сохраняет завершённую игру вместе с её итогами и результатом турнирной партии в одной
+	// транзакции. Профили игроков читаются с блокировкой строк и передаются в settle, поэтому две
+	// одновременно завершённые игры одного игрока не посчитают рейтинг от одного и того же значения.
+	// Ошибка settle отменяет завершение игры.
+	Finish(game *Game, settle SettleFunc) error
 	GetByID(id string) (*Game, error)
-	GetAvailableGames() ([]*Game, error)
+	// GetByInlineMessageID возвращает последнюю игру, начатую в inline-сообщении, или ErrGameNotFound.
+	GetByInlineMessageID(inlineMessageID string) (*Game, error)
+	// GetAvailableGames возвращает ожидающие соперника игры из личных чатов: к игре с общей доской
+	// присоединяются с этой доски.
+	GetAvailableGames(filter GameFilter) (*GamePage, error)
 	GetActiveGamesByUser(userID string) ([]*Game, error)
+	GetFinishedGamesByUser(userID string) ([]GameResult, error)
+}
+
+// GameOrigin - откуда взялась игра. Match - пара из очереди быстрой игры: оба игрока уходят из очереди,
+// а если кого-то из них там уже нет, Start возвращает ErrMatchTaken. Challenge - принятый вызов:
+// ответ сохраняется, только если вызов ещё ждёт ответа, иначе Start возвращает ErrChallengeClosed.
+type GameOrigin struct {
+	Match     *Match
+	Challenge *Challenge
+}
+
+// GameSettlement - итоги завершённой игры, которые записываются вместе с ней.
+type GameSettlement struct {
+	RatingChanges []RatingChange
+	Notifications []Notification
+}
+
+// SettleFunc считает итоги игры по профилям её игроков; игроки без профиля в players отсутствуют.
+// award выдаёт награды в той же транзакции и возвращает только те, которых у игроков ещё не было:
+// одновременно завершённая игра могла выдать ту же награду раньше.
+type SettleFunc func(players map[string]*User, award AwardFunc) (*GameSettlement, error)
+
+// AwardFunc сохраняет награды и возвращает действительно выданные, уже полученные пропускаются.
+type AwardFunc func(achievements []UserAchievement) ([]UserAchievement, error)
+
+type GameFilter struct {
+	Limit            int
+	Offset           int
+	NewestFirst      bool
+	ExcludeCreatorID string
+	// ViewerID скрывает игры создателей, с которыми у этого пользователя есть блокировка в любую сторону.
+	ViewerID string
+	// MinRating и MaxRating ограничивают рейтинг создателя игры; 0 - без ограничения.
+	MinRating int
+	MaxRating int
+}
+
+type GamePage struct {
+	Games []*Game
+	Total int
+}
+
+type GameArchiveRepository interface {
+	ArchiveFinishedGames(before time.Time, limit int) (int, error)
+	RestoreGame(id string) (*Game, error)
 }
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeasonCarryOverPercent - какая доля отклонения рейтинга от DefaultRating переносится в следующий сезон.
+const SeasonCarryOverPercent = 50
+
+// Season - соревновательный сезон. Номер сезона служит идентификатором, ArchivedAt заполняется,
+// когда итоговая таблица сохранена и начат следующий сезон.
+type Season struct {
+	ID         int
+	StartsAt   time.Time
+	EndsAt     time.Time
+	ArchivedAt *time.Time
+}
+
+// SeasonStanding - место игрока в итоговой таблице сезона. Rating - рейтинг на конец сезона, до сброса.
+type SeasonStanding struct {
+	SeasonID    int
+	UserID      string
+	DisplayName string
+	Rank        int
+	Rating      int
+}
+
+type SeasonRepository interface {
+	// Current возвращает текущий сезон или ErrSeasonNotFound, если сезонов ещё не было.
+	Current() (*Season, error)
+	GetByID(id int) (*Season, error)
+	Create(s *Season) error
+	// Rollover строит итоговую таблицу сезона, сохраняет её, мягко сбрасывает рейтинги всех игроков и
+	// открывает следующий сезон. Таблица строится в той же транзакции, что и сброс, чтобы доигранная в это
+	// время партия не разошлась с сохранёнными рейтингами. Если сезон уже закрыт, возвращает ErrSeasonClosed.
+	Rollover(finished, next *Season, notify SeasonNotifyFunc) ([]SeasonStanding, error)
+	GetStandings(seasonID, limit int) ([]SeasonStanding, error)
+	GetUserStandings(userID string) ([]SeasonStanding, error)
+}
+
+// SeasonNotifyFunc готовит уведомления игрокам по итоговой таблице сезона.
+type SeasonNotifyFunc func(standings []SeasonStanding) ([]Notification, error)
+
+func (s *Season) Name() string {
+	return fmt.Sprintf("Сезон %d", s.ID)
+}
+
+// NextSeason открывает сезон сразу после окончания текущего. Если бэкенд простоял дольше сезона,
+// новый сезон начинается сейчас, чтобы не получить уже закончившийся.
+func NextSeason(current *Season, length time.Duration, now time.Time) *Season {
+	startsAt := current.EndsAt
+	if !now.Before(startsAt.Add(length)) {
+		startsAt = now
+	}
+	return &Season{ID: current.ID + 1, StartsAt: startsAt, EndsAt: startsAt.Add(length)}
+}
+
+// SoftResetRating приближает рейтинг к начальному: сохраняется SeasonCarryOverPercent процентов отклонения.
+func SoftResetRating(rating int) int {
+	return DefaultRating + (rating-DefaultRating)*SeasonCarryOverPercent/100
+}
+
+// SeasonBadge возвращает знак отличия за место в итоговой таблице сезона или пустую строку.
+func SeasonBadge(rank int) string {
+	switch {
+	case rank == 1:
+		return "🥇"
+	case rank == 2:
+		return "🥈"
+	case rank == 3:
+		return "🥉"
+	case rank >= 4 && rank <= 10:
+		return "🏅"
+	}
+	return ""
+}
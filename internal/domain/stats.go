@@ -0,0 +1,109 @@
+package domain
+
+import "time"
+
+// GameResult - итог завершённой игры, достаточный для подсчёта статистики.
+// Строится как из таблицы games, так и из архива.
+type GameResult struct {
+	GameID     string
+	PlayerXID  string
+	PlayerOID  string
+	WinnerID   string
+	Moves      int
+	CreatedAt  time.Time
+	FinishedAt time.Time
+}
+
+type PlayerStats struct {
+	Games         int
+	Wins          int
+	Losses        int
+	Draws         int
+	GamesAsX      int
+	WinsAsX       int
+	GamesAsO      int
+	WinsAsO       int
+	CurrentStreak int
+	BestStreak    int
+	AverageMoves  float64
+}
+
+func (g *Game) Result() GameResult {
+	result := GameResult{
+		GameID:     g.ID,
+		CreatedAt:  g.CreatedAt,
+		FinishedAt: g.UpdatedAt,
+	}
+
+	for _, p := range g.Players {
+		switch p.Symbol {
+		case "X":
+			result.PlayerXID = p.ID
+		case "O":
+			result.PlayerOID = p.ID
+		}
+	}
+
+	if winner := g.Winner(); winner != nil {
+		result.WinnerID = winner.ID
+	}
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if g.Board[i][j] != "" {
+				result.Moves++
+			}
+		}
+	}
+
+	return result
+}
+
+// NewPlayerStats считает статистику игрока; results должны быть упорядочены по времени окончания.
+func NewPlayerStats(userID string, results []GameResult) PlayerStats {
+	var stats PlayerStats
+	var totalMoves, countedGames int
+
+	for _, r := range results {
+		stats.Games++
+
+		won := r.WinnerID == userID
+		switch {
+		case won:
+			stats.Wins++
+			stats.CurrentStreak++
+			if stats.CurrentStreak > stats.BestStreak {
+				stats.BestStreak = stats.CurrentStreak
+			}
+		case r.WinnerID == "":
+			stats.Draws++
+			stats.CurrentStreak = 0
+		default:
+			stats.Losses++
+			stats.CurrentStreak = 0
+		}
+
+		if r.PlayerXID == userID {
+			stats.GamesAsX++
+			if won {
+				stats.WinsAsX++
+			}
+		} else {
+			stats.GamesAsO++
+			if won {
+				stats.WinsAsO++
+			}
+		}
+
+		if r.Moves > 0 {
+			totalMoves += r.Moves
+			countedGames++
+		}
+	}
+
+	if countedGames > 0 {
+		stats.AverageMoves = float64(totalMoves) / float64(countedGames)
+	}
+
+	return stats
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type TournamentFormat string
+
+const (
+	TournamentRoundRobin TournamentFormat = "round_robin"
+	TournamentKnockout   TournamentFormat = "knockout"
+	TournamentSwiss      TournamentFormat = "swiss"
+	TournamentArena      TournamentFormat = "arena"
+)
+
+type TournamentStatus string
+
+const (
+	TournamentRegistration TournamentStatus = "registration"
+	TournamentRunning      TournamentStatus = "running"
+	TournamentFinished     TournamentStatus = "finished"
+	TournamentCancelled    TournamentStatus = "cancelled"
+)
+
+const (
+	TournamentMinPlayers = 2
+	TournamentMaxPlayers = 32
+)
+
+// Tournament - турнир. Round - номер текущего тура (0 до старта), Rounds - общее число туров.
+// Для швейцарской системы его может задать организатор, для остальных оно определяется при старте.
+// У арены туров нет: все партии относятся к первому туру, а длительность задаёт Duration.
+type Tournament struct {
+	ID            string
+	Name          string
+	Format        TournamentFormat
+	Status        TournamentStatus
+	OrganizerID   string
+	OrganizerName string
+	StartsAt      time.Time
+	Round         int
+	Rounds        int
+	Duration      time.Duration
+	CreatedAt     time.Time
+	FinishedAt    *time.Time
+}
+
+// TournamentPlayer - участник турнира. Seed - номер посева, назначается при старте по рейтингу.
+type TournamentPlayer struct {
+	TournamentID string
+	UserID       string
+	UserName     string
+	Seed         int
+	JoinedAt     time.Time
+}
+
+// TournamentGame - партия тура, FirstID играет крестиками. У пропуска тура (bye) нет соперника и игры,
+// победа засчитывается сразу. Завершённая партия с пустым WinnerID - ничья.
+type TournamentGame struct {
+	ID           string
+	TournamentID string
+	Round        int
+	GameID       string
+	FirstID      string
+	SecondID     string
+	WinnerID     string
+	Finished     bool
+	CreatedAt    time.Time
+}
+
+type TournamentRepository interface {
+	Create(t *Tournament) error
+	GetByID(id string) (*Tournament, error)
+	ListByStatus(statuses ...TournamentStatus) ([]*Tournament, error)
+	AddPlayer(p TournamentPlayer, limit int) error
+	RemovePlayer(tournamentID, userID string) (bool, error)
+	GetPlayers(tournamentID string) ([]TournamentPlayer, error)
+	SetSeeds(players []TournamentPlayer) error
+	GetGames(tournamentID string) ([]TournamentGame, error)
+	// StartRound сохраняет состояние турнира и партии тура, если турнир всё ещё в туре fromRound.
+	StartRound(t *Tournament, fromRound int, games []TournamentGame, notifications ...Notification) error
+	// RecordResult отмечает результат партии по идентификатору игры, если он ещё не засчитан, и возвращает
+	// турнир, к которому она относится, или пустую строку для обычной игры. Результат партии, завершённой
+	// через GameRepository.Finish, уже засчитан в той же транзакции.
+	RecordResult(gameID, winnerID string) (string, error)
+	Finish(t *Tournament, notifications ...Notification) error
+}
+
+func NewTournament(organizerID, organizerName, name string, format TournamentFormat, startsAt time.Time) (*Tournament, error) {
+	switch format {
+	case TournamentRoundRobin, TournamentKnockout, TournamentSwiss, TournamentArena:
+	default:
+		return nil, ErrInvalidTournament
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Турнир"
+	}
+
+	return &Tournament{
+		Name:          name,
+		Format:        format,
+		Status:        TournamentRegistration,
+		OrganizerID:   organizerID,
+		OrganizerName: organizerName,
+		StartsAt:      startsAt,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// ParseTournamentFormat принимает короткие названия форматов из команд бота.
+func ParseTournamentFormat(text string) (TournamentFormat, error) {
+	switch strings.ToLower(text) {
+	case "round", "round_robin", "rr":
+		return TournamentRoundRobin, nil
+	case "knockout", "ko", "cup":
+		return TournamentKnockout, nil
+	case "swiss":
+		return TournamentSwiss, nil
+	case "arena":
+		return TournamentArena, nil
+	}
+	return "", ErrInvalidTournament
+}
+
+func (t *Tournament) CheckRegistration(now time.Time) error {
+	if t.Status != TournamentRegistration || !now.Before(t.StartsAt) {
+		return ErrRegistrationClosed
+	}
+	return nil
+}
+
+// EndsAt возвращает время окончания арены: после него новые партии не создаются.
+func (t *Tournament) EndsAt() time.Time {
+	return t.StartsAt.Add(t.Duration)
+}
+
+// Opponent возвращает соперника игрока в партии.
+func (g TournamentGame) Opponent(userID string) string {
+	if g.FirstID == userID {
+		return g.SecondID
+	}
+	return g.FirstID
+}
+
+func (g TournamentGame) IsBye() bool {
+	return g.SecondID == ""
+}
+
+func (g TournamentGame) Involves(userID string) bool {
+	return g.FirstID == userID || g.SecondID == userID
+}
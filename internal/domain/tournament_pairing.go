@@ -0,0 +1,246 @@
+package domain
+
+import (
+	"sort"
+)
+
+// SeedPlayers назначает посев по рейтингу, при равном рейтинге раньше идёт тот, кто раньше записался.
+func SeedPlayers(players []TournamentPlayer, ratings map[string]int) []TournamentPlayer {
+	seeded := append([]TournamentPlayer(nil), players...)
+	sort.SliceStable(seeded, func(i, j int) bool {
+		ri, rj := ratings[seeded[i].UserID], ratings[seeded[j].UserID]
+		if ri != rj {
+			return ri > rj
+		}
+		return seeded[i].JoinedAt.Before(seeded[j].JoinedAt)
+	})
+	for i := range seeded {
+		seeded[i].Seed = i + 1
+	}
+	return seeded
+}
+
+// TournamentRounds возвращает число туров для формата и количества участников. Для швейцарской
+// системы это значение по умолчанию: столько туров, сколько нужно, чтобы выявить единственного лидера.
+func TournamentRounds(format TournamentFormat, players int) int {
+	switch format {
+	case TournamentRoundRobin:
+		return roundRobinRounds(players)
+	case TournamentKnockout, TournamentSwiss:
+		rounds := 0
+		for size := 1; size < players; size *= 2 {
+			rounds++
+		}
+		return rounds
+	}
+	return 0
+}
+
+// SwissRounds ограничивает заданное организатором число туров: туров не может быть больше,
+// чем в круговом турнире, иначе повторные встречи неизбежны.
+func SwissRounds(requested, players int) int {
+	if requested <= 0 {
+		requested = TournamentRounds(TournamentSwiss, players)
+	}
+	if max := roundRobinRounds(players); requested > max {
+		return max
+	}
+	return requested
+}
+
+func roundRobinRounds(players int) int {
+	if players%2 == 0 {
+		return players - 1
+	}
+	return players
+}
+
+// RoundRobinPairings строит пары тура круговой системы методом вращения: первый по посеву
+// остаётся на месте, остальные сдвигаются по кругу, поэтому за все туры каждый встречается с каждым.
+// При нечётном числе участников один из них в каждом туре пропускает игру (пустой второй игрок).
+func RoundRobinPairings(players []TournamentPlayer, round int) [][2]string {
+	ids := seedOrder(players)
+	if len(ids)%2 != 0 {
+		ids = append(ids, "")
+	}
+	n := len(ids)
+	if n < 2 {
+		return nil
+	}
+
+	circle := make([]string, n)
+	circle[0] = ids[0]
+	for i := 1; i < n; i++ {
+		circle[i] = ids[1+(i-1+round-1)%(n-1)]
+	}
+
+	var pairs [][2]string
+	for i := 0; i < n/2; i++ {
+		first, second := circle[i], circle[n-1-i]
+		if i == 0 && round%2 == 0 {
+			first, second = second, first
+		}
+		pairs = append(pairs, byeLast(first, second))
+	}
+	return pairs
+}
+
+// KnockoutPairings строит пары тура на выбывание: сильнейший по посеву из оставшихся играет со слабейшим.
+// При нечётном числе участников сильнейший проходит в следующий тур без игры.
+func KnockoutPairings(alive []TournamentPlayer) [][2]string {
+	ids := seedOrder(alive)
+
+	var pairs [][2]string
+	if len(ids)%2 != 0 {
+		pairs = append(pairs, [2]string{ids[0], ""})
+		ids = ids[1:]
+	}
+	for i := 0; i < len(ids)/2; i++ {
+		pairs = append(pairs, [2]string{ids[i], ids[len(ids)-1-i]})
+	}
+	return pairs
+}
+
+// KnockoutAlive возвращает участников, ещё не проигравших ни одной пары.
+func KnockoutAlive(players []TournamentPlayer, games []TournamentGame) []TournamentPlayer {
+	lost := map[string]bool{}
+	for _, g := range games {
+		if g.Finished && g.WinnerID != "" && !g.IsBye() {
+			lost[g.Opponent(g.WinnerID)] = true
+		}
+	}
+
+	var alive []TournamentPlayer
+	for _, p := range players {
+		if !lost[p.UserID] {
+			alive = append(alive, p)
+		}
+	}
+	return alive
+}
+
+// KnockoutReplays возвращает пары тура на выбывание, все партии которых закончились вничью.
+// Такие пары переигрываются со сменой очерёдности, пока не определится победитель.
+func KnockoutReplays(games []TournamentGame, round int) [][2]string {
+	type pairState struct {
+		last    TournamentGame
+		decided bool
+		open    bool
+	}
+	states := map[[2]string]*pairState{}
+	var order [][2]string
+
+	for _, g := range games {
+		if g.Round != round || g.IsBye() {
+			continue
+		}
+		key := pairKey(g.FirstID, g.SecondID)
+		state, ok := states[key]
+		if !ok {
+			state = &pairState{}
+			states[key] = state
+			order = append(order, key)
+		}
+		state.last = g
+		if !g.Finished {
+			state.open = true
+		} else if g.WinnerID != "" {
+			state.decided = true
+		}
+	}
+
+	var replays [][2]string
+	for _, key := range order {
+		state := states[key]
+		if !state.decided && !state.open {
+			replays = append(replays, [2]string{state.last.SecondID, state.last.FirstID})
+		}
+	}
+	return replays
+}
+
+// RoundFinished проверяет, что все партии тура сыграны.
+func RoundFinished(games []TournamentGame, round int) bool {
+	for _, g := range games {
+		if g.Round == round && !g.Finished {
+			return false
+		}
+	}
+	return true
+}
+
+func seedOrder(players []TournamentPlayer) []string {
+	sorted := append([]TournamentPlayer(nil), players...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Seed < sorted[j].Seed })
+
+	ids := make([]string, len(sorted))
+	for i, p := range sorted {
+		ids[i] = p.UserID
+	}
+	return ids
+}
+
+func byeLast(first, second string) [2]string {
+	if first == "" {
+		return [2]string{second, ""}
+	}
+	return [2]string{first, second}
+}
+
+func pairKey(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// ArenaPairings сводит свободных участников арены. Свободные упорядочиваются по таблице, и каждый
+// получает ближайшего соперника, кроме того, с кем только что играл. Если другого соперника нет,
+// а кто-то ещё доигрывает, участник ждёт его; без этого двое освободившихся сразу играли бы снова.
+func ArenaPairings(players []TournamentPlayer, games []TournamentGame) [][2]string {
+	busy := map[string]bool{}
+	last := map[string]string{}
+	for _, g := range games {
+		if g.IsBye() {
+			continue
+		}
+		if !g.Finished {
+			busy[g.FirstID] = true
+			busy[g.SecondID] = true
+		}
+		last[g.FirstID] = g.SecondID
+		last[g.SecondID] = g.FirstID
+	}
+
+	var idle []string
+	for _, st := range ComputeStandings(TournamentArena, players, games) {
+		if !busy[st.Player.UserID] {
+			idle = append(idle, st.Player.UserID)
+		}
+	}
+
+	colors := colorHistory(games)
+	var pairs [][2]string
+	for len(idle) >= 2 {
+		first := idle[0]
+
+		pick := -1
+		for i := 1; i < len(idle); i++ {
+			if last[first] != idle[i] {
+				pick = i
+				break
+			}
+		}
+		if pick < 0 {
+			if len(busy) > 0 {
+				idle = idle[1:]
+				continue
+			}
+			pick = 1
+		}
+
+		pairs = append(pairs, assignColors([2]string{first, idle[pick]}, colors))
+		idle = append(append([]string(nil), idle[1:pick]...), idle[pick+1:]...)
+	}
+	return pairs
+}
@@ -0,0 +1,131 @@
+package domain
+
+import "sort"
+
+// ArenaStreakWins - после стольких побед подряд очки на арене удваиваются до первой не-победы.
+const ArenaStreakWins = 2
+
+// TournamentStanding - строка турнирной таблицы. Очки: победа и пропуск тура - 1, ничья - ½.
+// На арене победа даёт 2 очка, ничья - 1, а в серии побед - вдвое больше.
+// Buchholz - сумма очков соперников, SonnebornBerger - сумма очков побеждённых соперников
+// и половины очков тех, с кем сыграна ничья.
+type TournamentStanding struct {
+	Player          TournamentPlayer
+	Score           float64
+	Wins            int
+	Draws           int
+	Losses          int
+	Buchholz        float64
+	SonnebornBerger float64
+	// Streak - текущая серия побед подряд.
+	Streak int
+	// LastRound - последний тур, в котором участник играл; в турнире на выбывание показывает, как далеко он прошёл.
+	LastRound  int
+	Eliminated bool
+}
+
+// ComputeStandings считает турнирную таблицу по сыгранным партиям. В круговом турнире места делятся
+// по очкам, затем по коэффициенту Зоннеборна-Бергера, числу побед и посеву; в швейцарском перед
+// Зоннеборном-Бергером учитывается Бухгольц. В турнире на выбывание выше тот, кто дольше оставался в сетке.
+func ComputeStandings(format TournamentFormat, players []TournamentPlayer, games []TournamentGame) []TournamentStanding {
+	index := make(map[string]int, len(players))
+	standings := make([]TournamentStanding, len(players))
+	for i, p := range players {
+		index[p.UserID] = i
+		standings[i].Player = p
+	}
+
+	for _, g := range games {
+		if !g.Finished {
+			continue
+		}
+		for _, id := range []string{g.FirstID, g.SecondID} {
+			i, ok := index[id]
+			if !ok {
+				continue
+			}
+			s := &standings[i]
+			if g.Round > s.LastRound {
+				s.LastRound = g.Round
+			}
+			onFire := s.Streak >= ArenaStreakWins
+			switch g.WinnerID {
+			case id:
+				s.Wins++
+				s.Score += gamePoints(format, 1, onFire)
+				s.Streak++
+			case "":
+				s.Draws++
+				s.Score += gamePoints(format, 0.5, onFire)
+				s.Streak = 0
+			default:
+				s.Losses++
+				s.Streak = 0
+				if format == TournamentKnockout {
+					s.Eliminated = true
+				}
+			}
+		}
+	}
+
+	for _, g := range games {
+		if !g.Finished || g.IsBye() {
+			continue
+		}
+		first, okFirst := index[g.FirstID]
+		second, okSecond := index[g.SecondID]
+		if !okFirst || !okSecond {
+			continue
+		}
+		standings[first].Buchholz += standings[second].Score
+		standings[second].Buchholz += standings[first].Score
+
+		switch g.WinnerID {
+		case g.FirstID:
+			standings[first].SonnebornBerger += standings[second].Score
+		case g.SecondID:
+			standings[second].SonnebornBerger += standings[first].Score
+		default:
+			standings[first].SonnebornBerger += standings[second].Score / 2
+			standings[second].SonnebornBerger += standings[first].Score / 2
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if format == TournamentKnockout {
+			if a.Eliminated != b.Eliminated {
+				return !a.Eliminated
+			}
+			if a.LastRound != b.LastRound {
+				return a.LastRound > b.LastRound
+			}
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if format == TournamentSwiss && a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Player.Seed < b.Player.Seed
+	})
+
+	return standings
+}
+
+func gamePoints(format TournamentFormat, points float64, onFire bool) float64 {
+	if format != TournamentArena {
+		return points
+	}
+	points *= 2
+	if onFire {
+		points *= 2
+	}
+	return points
+}
@@ -0,0 +1,192 @@
+package domain
+
+import "sort"
+
+// swissSearchSteps ограничивает перебор пар одного тура. Жеребьёвка идёт под блокировкой турнира
+// прямо в ходе, завершившем тур, а полный перебор растёт факториально от числа участников.
+const swissSearchSteps = 20000
+
+// SwissPairings строит пары очередного тура швейцарской системы. Участники упорядочиваются по очкам
+// и посеву; при нечётном числе тур пропускает самый нижний из тех, кто ещё не пропускал, а если
+// тогда без повторных встреч не разбить, - следующий снизу. Каждый сверху вниз получает ближайшего
+// соперника, с которым ещё не встречался, с перебором с возвратом не дольше swissSearchSteps шагов.
+// Если так разбить всех не удалось, каждый сверху вниз берёт ближайшего, с кем не играл, а если
+// таких не осталось - просто ближайшего.
+// Первым в паре (крестиками) ставится тот, кто реже играл X. Результат зависит только от
+// участников и сыгранных партий, поэтому жеребьёвку можно проверить.
+func SwissPairings(players []TournamentPlayer, games []TournamentGame) [][2]string {
+	standings := ComputeStandings(TournamentSwiss, players, games)
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].Player.Seed < standings[j].Player.Seed
+	})
+
+	met := map[[2]string]bool{}
+	hadBye := map[string]bool{}
+	for _, g := range games {
+		if g.IsBye() {
+			hadBye[g.FirstID] = true
+			continue
+		}
+		met[pairKey(g.FirstID, g.SecondID)] = true
+	}
+
+	ids := make([]string, len(standings))
+	for i, st := range standings {
+		ids[i] = st.Player.UserID
+	}
+
+	// кандидаты на пропуск тура: снизу вверх сначала не пропускавшие, затем остальные
+	byes := []int{-1}
+	if len(ids)%2 != 0 {
+		byes = nil
+		for i := len(ids) - 1; i >= 0; i-- {
+			if !hadBye[ids[i]] {
+				byes = append(byes, i)
+			}
+		}
+		for i := len(ids) - 1; i >= 0; i-- {
+			if hadBye[ids[i]] {
+				byes = append(byes, i)
+			}
+		}
+	}
+
+	search := &swissSearch{met: met, steps: swissSearchSteps}
+	bye, matched, ok := -1, [][2]string(nil), false
+	for _, candidate := range byes {
+		bye = candidate
+		if matched, ok = search.pair(withoutIndex(ids, bye)); ok || search.steps <= 0 {
+			break
+		}
+	}
+	if !ok {
+		bye = byes[0]
+		matched = pairGreedy(withoutIndex(ids, bye), met)
+	}
+
+	var pairs [][2]string
+	if bye >= 0 {
+		pairs = append(pairs, [2]string{ids[bye], ""})
+	}
+
+	colors := colorHistory(games)
+	for _, pair := range matched {
+		pairs = append(pairs, assignColors(pair, colors))
+	}
+	return pairs
+}
+
+// withoutIndex возвращает копию ids без элемента i; при i < 0 - копию целиком.
+func withoutIndex(ids []string, i int) []string {
+	if i < 0 {
+		return append([]string(nil), ids...)
+	}
+	rest := make([]string, 0, len(ids)-1)
+	rest = append(rest, ids[:i]...)
+	return append(rest, ids[i+1:]...)
+}
+
+// swissSearch - перебор с возвратом пар без повторных встреч; steps - сколько шагов перебора осталось.
+type swissSearch struct {
+	met   map[[2]string]bool
+	steps int
+}
+
+// pair разбивает ids на пары без повторных встреч. false означает, что разбиения нет
+// или на его поиск не хватило шагов.
+func (s *swissSearch) pair(ids []string) ([][2]string, bool) {
+	if len(ids) == 0 {
+		return nil, true
+	}
+
+	first := ids[0]
+	for i := 1; i < len(ids); i++ {
+		if s.steps <= 0 {
+			return nil, false
+		}
+		s.steps--
+
+		if s.met[pairKey(first, ids[i])] {
+			continue
+		}
+
+		rest := make([]string, 0, len(ids)-2)
+		rest = append(rest, ids[1:i]...)
+		rest = append(rest, ids[i+1:]...)
+
+		if pairs, ok := s.pair(rest); ok {
+			return append([][2]string{{first, ids[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// pairGreedy сводит каждого сверху вниз с ближайшим, с кем он ещё не играл, а если таких нет - с ближайшим.
+func pairGreedy(ids []string, met map[[2]string]bool) [][2]string {
+	var pairs [][2]string
+	for len(ids) > 1 {
+		partner := 1
+		for i := 1; i < len(ids); i++ {
+			if !met[pairKey(ids[0], ids[i])] {
+				partner = i
+				break
+			}
+		}
+		pairs = append(pairs, [2]string{ids[0], ids[partner]})
+		ids = withoutIndex(withoutIndex(ids, partner), 0)
+	}
+	return pairs
+}
+
+type colorStats struct {
+	balance int // сколько раз X минус сколько раз O
+	last    string
+}
+
+func colorHistory(games []TournamentGame) map[string]*colorStats {
+	colors := map[string]*colorStats{}
+	get := func(id string) *colorStats {
+		if colors[id] == nil {
+			colors[id] = &colorStats{}
+		}
+		return colors[id]
+	}
+
+	for _, g := range games {
+		if g.IsBye() {
+			continue
+		}
+		x, o := get(g.FirstID), get(g.SecondID)
+		x.balance++
+		x.last = "X"
+		o.balance--
+		o.last = "O"
+	}
+	return colors
+}
+
+// assignColors ставит первым (X) того, кто реже играл крестиками, затем того, кто играл ноликами
+// в прошлый раз; при полном равенстве X остаётся у стоящего выше в таблице.
+func assignColors(pair [2]string, colors map[string]*colorStats) [2]string {
+	a, b := colors[pair[0]], colors[pair[1]]
+	if a == nil {
+		a = &colorStats{}
+	}
+	if b == nil {
+		b = &colorStats{}
+	}
+
+	if a.balance != b.balance {
+		if b.balance < a.balance {
+			return [2]string{pair[1], pair[0]}
+		}
+		return pair
+	}
+	if a.last == "X" && b.last != "X" {
+		return [2]string{pair[1], pair[0]}
+	}
+	return pair
+}
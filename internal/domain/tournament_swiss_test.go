@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func swissPlayers(n int) []TournamentPlayer {
+	players := make([]TournamentPlayer, n)
+	for i := range players {
+		players[i] = TournamentPlayer{UserID: fmt.Sprintf("p%02d", i+1), Seed: i + 1}
+	}
+	return players
+}
+
+func swissGame(first, second, winner string) TournamentGame {
+	return TournamentGame{FirstID: first, SecondID: second, WinnerID: winner, Finished: true}
+}
+
+// swissBottomTrio - 32 участника, где трое последних уже сыграли со всеми, кроме друг друга, и всем
+// проиграли. Разбить их без повторной встречи нельзя, но перебор узнаёт это только после всех
+// вариантов для 29 участников выше.
+func swissBottomTrio() []TournamentGame {
+	var games []TournamentGame
+	for _, loser := range []string{"p30", "p31", "p32"} {
+		for i := 1; i <= 29; i++ {
+			winner := fmt.Sprintf("p%02d", i)
+			games = append(games, swissGame(winner, loser, winner))
+		}
+	}
+	return games
+}
+
+func TestSwissPairings(t *testing.T) {
+	bottomTrio := [][2]string{}
+	for i := 1; i < 28; i += 2 {
+		bottomTrio = append(bottomTrio, [2]string{fmt.Sprintf("p%02d", i), fmt.Sprintf("p%02d", i+1)})
+	}
+	bottomTrio = append(bottomTrio, [2]string{"p30", "p29"}, [2]string{"p31", "p32"})
+
+	tests := []struct {
+		name    string
+		players int
+		games   []TournamentGame
+		want    [][2]string
+	}{
+		{
+			name:    "первый тур по посеву",
+			players: 4,
+			want:    [][2]string{{"p01", "p02"}, {"p03", "p04"}},
+		},
+		{
+			name:    "пропуск у нижнего",
+			players: 5,
+			want:    [][2]string{{"p05", ""}, {"p01", "p02"}, {"p03", "p04"}},
+		},
+		{
+			name:    "пропуск у нижнего из не пропускавших",
+			players: 5,
+			games: []TournamentGame{
+				{FirstID: "p05", Finished: true, WinnerID: "p05"},
+				swissGame("p01", "p02", "p01"),
+				swissGame("p03", "p04", "p03"),
+			},
+			want: [][2]string{{"p04", ""}, {"p01", "p03"}, {"p02", "p05"}},
+		},
+		{
+			name:    "без повторных встреч",
+			players: 4,
+			games: []TournamentGame{
+				swissGame("p01", "p02", "p01"),
+				swissGame("p03", "p04", "p03"),
+			},
+			want: [][2]string{{"p01", "p03"}, {"p02", "p04"}},
+		},
+		{
+			name:    "пропуск выбирается вместе с парами",
+			players: 5,
+			games: []TournamentGame{
+				swissGame("p01", "p02", "p01"),
+				swissGame("p01", "p03", "p01"),
+				swissGame("p01", "p04", "p01"),
+			},
+			want: [][2]string{{"p04", ""}, {"p05", "p01"}, {"p02", "p03"}},
+		},
+		{
+			name:    "повтор неизбежен",
+			players: 32,
+			games:   swissBottomTrio(),
+			want:    bottomTrio,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SwissPairings(swissPlayers(tt.players), tt.games)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SwissPairings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwissPairingsSearchIsBounded(t *testing.T) {
+	games := swissBottomTrio()
+
+	start := time.Now()
+	SwissPairings(swissPlayers(32), games)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("жеребьёвка 32 участников заняла %s", elapsed)
+	}
+}
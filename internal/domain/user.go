@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Идентификатор пользователя - user_<id пользователя Telegram>. Раньше им служил чат (chat_<id>),
+// для личных чатов эти числа совпадают, поэтому старые идентификаторы переводятся заменой префикса.
+const (
+	userIDPrefix       = "user_"
+	legacyUserIDPrefix = "chat_"
+)
+
+// NormalizeUserID переводит идентификатор старого формата (chat_<id>) в текущий.
+func NormalizeUserID(id string) string {
+	if strings.HasPrefix(id, legacyUserIDPrefix) {
+		return userIDPrefix + strings.TrimPrefix(id, legacyUserIDPrefix)
+	}
+	return id
+}
+
+type User struct {
+	ID          string
+	DisplayName string
+	Username    string
+	Language    string
+	Settings    map[string]string
+	Rating      int
+	RatedGames  int
+	CreatedAt   time.Time
+	LastSeenAt  time.Time
+}
+
+type UserRepository interface {
+	// Upsert создаёт профиль при первом обращении, а при повторных обновляет имя, язык и время визита.
+	Upsert(user *User) error
+	GetByID(id string) (*User, error)
+	GetByUsername(username string) (*User, error)
+	GetByIDs(ids []string) (map[string]*User, error)
+}
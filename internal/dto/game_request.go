@@ -1,14 +1,29 @@
 package dto
 
+// ListGamesRequest - страница списка игр. NewestFirst показывает сначала новые игры,
+// NearRating - только игры создателей с рейтингом, близким к рейтингу пользователя.
+type ListGamesRequest struct {
+	UserID      string
+	Page        int
+	NewestFirst bool
+	NearRating  bool
+}
+
 type CreateGameRequest struct {
-	UserID   string
-	UserName string
+	UserID          string
+	UserName        string
+	ChatID          string
+	InlineMessageID string
+	Rated           bool
 }
 
+// JoinGameRequest - присоединение к игре. Без GameID игра ищется по InlineMessageID.
 type JoinGameRequest struct {
-	UserID   string
-	UserName string
-	GameID   string
+	UserID          string
+	UserName        string
+	ChatID          string
+	InlineMessageID string
+	GameID          string
 }
 
 type MakeMoveRequest struct {
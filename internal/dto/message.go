@@ -1,23 +1,44 @@
 package dto
 
+// IncomingMessage - команда от бота. InlineMessageID - inline-сообщение, из которого пришла команда:
+// выбор результата inline-режима или нажатие кнопки под таким сообщением.
 type IncomingMessage struct {
-	UserID   string  `json:"userId"`
-	UserName string  `json:"userName,omitempty"`
-	Text     *string `json:"text,omitempty"`
-	Action   *string `json:"action,omitempty"`
+	UserID          string  `json:"userId"`
+	UserName        string  `json:"userName,omitempty"`
+	Username        string  `json:"username,omitempty"`
+	LanguageCode    string  `json:"languageCode,omitempty"`
+	ChatID          string  `json:"chatId,omitempty"`
+	ChatType        string  `json:"chatType,omitempty"`
+	InlineMessageID string  `json:"inlineMessageId,omitempty"`
+	Text            *string `json:"text,omitempty"`
+	Action          *string `json:"action,omitempty"`
 }
 
+// OutgoingMessage - сообщение пользователю. Ref связывает сообщения об одном и том же объекте,
+// например доску игры: бот редактирует ранее отправленное сообщение с тем же Ref вместо нового.
+// Layout задаёт раскладку клавиатуры: сколько кнопок из Buttons идёт в каждый ряд по порядку;
+// кнопки, не вошедшие в Layout, бот ставит по одной в ряд. ChatID - чат, куда отправить сообщение,
+// например групповой чат с общей доской; пустой ChatID означает личный чат пользователя UserID.
+// Сообщение с InlineMessageID бот не отправляет, а заменяет им это inline-сообщение.
 type OutgoingMessage struct {
-	UserID  string   `json:"userId"`
-	Text    string   `json:"text"`
-	Buttons []Button `json:"buttons"`
+	UserID          string   `json:"userId"`
+	ChatID          string   `json:"chatId,omitempty"`
+	InlineMessageID string   `json:"inlineMessageId,omitempty"`
+	Text            string   `json:"text"`
+	Buttons         []Button `json:"buttons"`
+	Layout          []int    `json:"layout,omitempty"`
+	Ref             string   `json:"ref,omitempty"`
 }
 
+// Button - кнопка клавиатуры. Кнопка без Action ничего не делает, например занятая клетка доски.
 type Button struct {
 	Text   string `json:"text"`
 	Action string `json:"action"`
 }
 
+// menuRowSize - сколько кнопок меню помещается в ряд.
+const menuRowSize = 3
+
 type OutgoingMessages struct {
 	Messages []OutgoingMessage `json:"messages"`
 }
@@ -27,7 +48,28 @@ func NewOutgoingMessage(userID, text string, buttons []Button) *OutgoingMessage
 		UserID:  userID,
 		Text:    text,
 		Buttons: buttons,
+		Layout:  rowsOf(len(buttons), menuRowSize),
+	}
+}
+
+// NewBoardMessage строит сообщение, в котором клавиатура начинается с рядов доски, а под ней идут кнопки меню.
+func NewBoardMessage(userID, text string, board [][]Button, buttons []Button) *OutgoingMessage {
+	msg := &OutgoingMessage{UserID: userID, Text: text}
+	for _, row := range board {
+		msg.Buttons = append(msg.Buttons, row...)
+		msg.Layout = append(msg.Layout, len(row))
+	}
+	msg.Buttons = append(msg.Buttons, buttons...)
+	msg.Layout = append(msg.Layout, rowsOf(len(buttons), menuRowSize)...)
+	return msg
+}
+
+func rowsOf(count, size int) []int {
+	var rows []int
+	for ; count > 0; count -= size {
+		rows = append(rows, min(count, size))
 	}
+	return rows
 }
 
 func NewOutgoingMessages(messages ...OutgoingMessage) *OutgoingMessages {
@@ -0,0 +1,8 @@
+package dto
+
+type TouchUserRequest struct {
+	UserID      string
+	DisplayName string
+	Username    string
+	Language    string
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+// SecretHeader - заголовок с общим секретом, по которому бот проверяет, что уведомление прислал бэкенд.
+const SecretHeader = "X-Delivery-Secret"
+
+// HTTPSender доставляет уведомления боту: тело запроса - готовое исходящее сообщение в JSON.
+type HTTPSender struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewHTTPSender(url, secret string) *HTTPSender {
+	return &HTTPSender{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *HTTPSender) Send(notification domain.Notification) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(notification.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Notification-ID", notification.ID)
+	if s.secret != "" {
+		req.Header.Set(SecretHeader, s.secret)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("бот ответил %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
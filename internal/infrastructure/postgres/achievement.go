@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type AchievementRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAchievementRepository(db *pgxpool.Pool) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+func (r *AchievementRepository) GetByUser(userID string) ([]domain.UserAchievement, error) {
+	query := `
+		SELECT user_id, code, game_id, awarded_at
+		FROM user_achievements
+		WHERE user_id = $1
+		ORDER BY awarded_at
+	`
+	return queryAchievements(r.db.Query(context.Background(), query, userID))
+}
+
+func (r *AchievementRepository) GetByGame(gameID string) ([]domain.UserAchievement, error) {
+	query := `
+		SELECT user_id, code, game_id, awarded_at
+		FROM user_achievements
+		WHERE game_id = $1
+		ORDER BY awarded_at
+	`
+	return queryAchievements(r.db.Query(context.Background(), query, gameID))
+}
+
+func queryAchievements(rows pgx.Rows, err error) ([]domain.UserAchievement, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []domain.UserAchievement
+	for rows.Next() {
+		var a domain.UserAchievement
+		if err := rows.Scan(&a.UserID, &a.Code, &a.GameID, &a.AwardedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, a)
+	}
+
+	return achievements, rows.Err()
+}
+
+// awardAchievements сохраняет награды и возвращает выданные; уже полученные пользователем награды пропускаются.
+func awardAchievements(ctx context.Context, q querier, achievements []domain.UserAchievement) ([]domain.UserAchievement, error) {
+	query := `
+		INSERT INTO user_achievements (user_id, code, game_id, awarded_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, code) DO NOTHING
+	`
+	var awarded []domain.UserAchievement
+	for _, a := range achievements {
+		tag, err := q.Exec(ctx, query, a.UserID, a.Code, a.GameID, a.AwardedAt)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() > 0 {
+			awarded = append(awarded, a)
+		}
+	}
+
+	return awarded, nil
+}
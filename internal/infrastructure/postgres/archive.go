@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+// GameArchiveRepository переносит завершённые игры в таблицу games_archive.
+// Полное состояние игры хранится сжатым, а участники, победитель и даты остаются
+// отдельными колонками, чтобы агрегированная статистика продолжала считаться.
+// Журнал событий и снимки игры удаляются вместе с ней: иначе хранилище событий продолжало бы
+// восстанавливать заархивированную игру. Восстановленная игра начинает журнал заново со своего состояния.
+type GameArchiveRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewGameArchiveRepository(db *pgxpool.Pool) *GameArchiveRepository {
+	return &GameArchiveRepository{db: db}
+}
+
+func (r *GameArchiveRepository) ArchiveFinishedGames(before time.Time, limit int) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE status = 'finished' AND updated_at < $1
+			AND (restored_at IS NULL OR restored_at < $1)
+		ORDER BY updated_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	games, err := queryGames(ctx, tx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, game := range games {
+		if err := archiveGame(ctx, tx, game); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return len(games), nil
+}
+
+func (r *GameArchiveRepository) RestoreGame(id string) (*domain.Game, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var data []byte
+	err = tx.QueryRow(ctx, `SELECT data FROM games_archive WHERE id = $1 FOR UPDATE`, id).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := decompressGame(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertGame(ctx, tx, game); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE games SET restored_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM games_archive WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return game, tx.Commit(ctx)
+}
+
+func archiveGame(ctx context.Context, tx pgx.Tx, game *domain.Game) error {
+	data, err := compressGame(game)
+	if err != nil {
+		return err
+	}
+
+	result := game.Result()
+
+	var winnerID *string
+	if result.WinnerID != "" {
+		winnerID = &result.WinnerID
+	}
+
+	query := `
+		INSERT INTO games_archive (id, player_x_id, player_o_id, winner_id, moves, created_at, finished_at, archived_at, data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), $8)
+	`
+	_, err = tx.Exec(ctx, query,
+		game.ID, result.PlayerXID, result.PlayerOID, winnerID, result.Moves, result.CreatedAt, result.FinishedAt, data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM game_events WHERE game_id = $1`, game.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM game_snapshots WHERE game_id = $1`, game.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM games WHERE id = $1`, game.ID)
+	return err
+}
+
+func archivedResultsByUser(ctx context.Context, q querier, userID string) ([]domain.GameResult, error) {
+	query := `
+		SELECT id, player_x_id, player_o_id, COALESCE(winner_id, ''), COALESCE(moves, 0), created_at, finished_at
+		FROM games_archive
+		WHERE player_x_id = $1 OR player_o_id = $1
+	`
+
+	rows, err := q.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.GameResult
+	for rows.Next() {
+		var r domain.GameResult
+		err := rows.Scan(&r.GameID, &r.PlayerXID, &r.PlayerOID, &r.WinnerID, &r.Moves, &r.CreatedAt, &r.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+func compressGame(game *domain.Game) ([]byte, error) {
+	state, err := json.Marshal(game)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(state); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompressGame(data []byte) (*domain.Game, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	state, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var game domain.Game
+	if err := json.Unmarshal(state, &game); err != nil {
+		return nil, err
+	}
+
+	// архив мог быть записан до перехода на идентификаторы пользователей Telegram
+	for i := range game.Players {
+		game.Players[i].ID = domain.NormalizeUserID(game.Players[i].ID)
+	}
+
+	return &game, nil
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BlockRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBlockRepository(db *pgxpool.Pool) *BlockRepository {
+	return &BlockRepository{db: db}
+}
+
+func (r *BlockRepository) Block(userID, blockedID string) error {
+	query := `
+		INSERT INTO blocks (user_id, blocked_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, blocked_id) DO NOTHING
+	`
+	_, err := r.db.Exec(context.Background(), query, userID, blockedID)
+	return err
+}
+
+func (r *BlockRepository) Unblock(userID, blockedID string) (bool, error) {
+	tag, err := r.db.Exec(context.Background(),
+		`DELETE FROM blocks WHERE user_id = $1 AND blocked_id = $2`, userID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *BlockRepository) IsBlocked(userID, otherID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (user_id = $1 AND blocked_id = $2) OR (user_id = $2 AND blocked_id = $1)
+		)
+	`
+
+	var blocked bool
+	err := r.db.QueryRow(context.Background(), query, userID, otherID).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *BlockRepository) ListBlocked(userID string) ([]string, error) {
+	rows, err := r.db.Query(context.Background(),
+		`SELECT blocked_id FROM blocks WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type ChallengeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewChallengeRepository(db *pgxpool.Pool) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+func (r *ChallengeRepository) Create(c *domain.Challenge, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO challenges (id, challenger_id, challenger_name, target_id, target_name, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.Exec(ctx, query,
+		c.ID, c.ChallengerID, c.ChallengerName, c.TargetID, c.TargetName, c.Status, c.CreatedAt, c.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *ChallengeRepository) GetByID(id string) (*domain.Challenge, error) {
+	query := `
+		SELECT id, challenger_id, challenger_name, target_id, target_name, status, COALESCE(game_id, ''), created_at, expires_at
+		FROM challenges
+		WHERE id = $1
+	`
+
+	var c domain.Challenge
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&c.ID, &c.ChallengerID, &c.ChallengerName, &c.TargetID, &c.TargetName, &c.Status, &c.GameID, &c.CreatedAt, &c.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *ChallengeRepository) Resolve(c *domain.Challenge, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := resolveChallenge(ctx, tx, c); err != nil {
+		return err
+	}
+
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// resolveChallenge сохраняет ответ на вызов, если вызов ещё ждёт ответа.
+func resolveChallenge(ctx context.Context, q querier, c *domain.Challenge) error {
+	query := `
+		UPDATE challenges
+		SET status = $1, game_id = NULLIF($2, '')
+		WHERE id = $3 AND status = 'pending'
+	`
+	tag, err := q.Exec(ctx, query, c.Status, c.GameID, c.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrChallengeClosed
+	}
+	return nil
+}
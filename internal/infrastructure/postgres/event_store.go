@@ -0,0 +1,464 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+// EventSourcedGameRepository хранит игру как поток событий и восстанавливает её сверткой.
+// Таблица games остаётся проекцией для запросов списков и обновляется в той же транзакции.
+type EventSourcedGameRepository struct {
+	*GameRepository
+	db               *pgxpool.Pool
+	snapshotInterval int
+}
+
+type eventPayload struct {
+	PlayerID        string             `json:"playerId,omitempty"`
+	PlayerName      string             `json:"playerName,omitempty"`
+	Symbol          string             `json:"symbol,omitempty"`
+	Rated           bool               `json:"rated,omitempty"`
+	ChatID          string             `json:"chatId,omitempty"`
+	InlineMessageID string             `json:"inlineMessageId,omitempty"`
+	Coordinate      *domain.Coordinate `json:"coordinate,omitempty"`
+}
+
+func NewEventSourcedGameRepository(db *pgxpool.Pool, snapshotInterval int) *EventSourcedGameRepository {
+	return &EventSourcedGameRepository{
+		GameRepository:   NewGameRepository(db),
+		db:               db,
+		snapshotInterval: snapshotInterval,
+	}
+}
+
+func (r *EventSourcedGameRepository) Create(game *domain.Game) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := leaveQueue(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(ctx, tx, game); err != nil {
+		return err
+	}
+
+	if err := r.appendEvents(ctx, tx, game); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	game.ClearPendingEvents()
+	return nil
+}
+
+// Start записывает события новой игры вместе с закрытием её источника в одной транзакции.
+func (r *EventSourcedGameRepository) Start(game *domain.Game, origin domain.GameOrigin, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := closeOrigin(ctx, tx, origin); err != nil {
+		return err
+	}
+	if err := leaveQueue(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := r.appendEvents(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	game.ClearPendingEvents()
+	return nil
+}
+
+// Join записывает событие присоединения вместе с уходом игроков из очереди быстрой игры.
+func (r *EventSourcedGameRepository) Join(game *domain.Game, notifications ...domain.Notification) error {
+	ctx := context.Background()
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = updateGame(ctx, tx, game)
+	if err == nil {
+		err = leaveQueue(ctx, tx, game)
+	}
+	if err == nil {
+		err = r.appendEvents(ctx, tx, game)
+	}
+	if err == nil {
+		err = insertNotifications(ctx, tx, notifications)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	game.ClearPendingEvents()
+	return nil
+}
+
+func (r *EventSourcedGameRepository) Update(game *domain.Game, notifications ...domain.Notification) error {
+	ctx := context.Background()
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// обновление проекции блокирует строку игры, поэтому номера событий не пересекутся
+	if err := updateGame(ctx, tx, game); err != nil {
+		return err
+	}
+
+	err = r.appendEvents(ctx, tx, game)
+	if err == nil {
+		err = insertNotifications(ctx, tx, notifications)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	game.ClearPendingEvents()
+	return nil
+}
+
+// Finish записывает события завершающего хода вместе с итогами игры в одной транзакции.
+func (r *EventSourcedGameRepository) Finish(game *domain.Game, settle domain.SettleFunc) error {
+	ctx := context.Background()
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = updateGame(ctx, tx, game)
+	if err == nil {
+		err = r.appendEvents(ctx, tx, game)
+	}
+	if err == nil {
+		err = settleGame(ctx, tx, game, settle)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	game.ClearPendingEvents()
+	return nil
+}
+
+func (r *EventSourcedGameRepository) GetByID(id string) (*domain.Game, error) {
+	ctx := context.Background()
+
+	snapshot, seq, err := r.latestSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := r.loadEvents(ctx, id, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot == nil && len(events) == 0 {
+		return r.importLegacyGame(ctx, id)
+	}
+
+	return domain.RebuildGame(id, snapshot, events)
+}
+
+// importLegacyGame переводит игру, созданную до включения журнала событий,
+// на хранение событиями: её текущее состояние становится базовым снимком с номером 0.
+func (r *EventSourcedGameRepository) importLegacyGame(ctx context.Context, id string) (*domain.Game, error) {
+	game, err := r.GameRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := saveSnapshot(ctx, r.db, game, 0); err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// GetByInlineMessageID находит игру по проекции, а состояние восстанавливает из событий, как GetByID.
+func (r *EventSourcedGameRepository) GetByInlineMessageID(inlineMessageID string) (*domain.Game, error) {
+	var id string
+	err := r.db.QueryRow(context.Background(),
+		`SELECT id FROM games WHERE inline_message_id = $1 ORDER BY created_at DESC LIMIT 1`, inlineMessageID,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(id)
+}
+
+func (r *EventSourcedGameRepository) GetEvents(gameID string) ([]domain.GameEvent, error) {
+	return r.loadEvents(context.Background(), gameID, 0)
+}
+
+// RebuildProjection пересчитывает игру по полному потоку событий без промежуточных снимков,
+// перезаписывает проекцию и снимки. Нужен после изменения правил игры, например CheckWin.
+func (r *EventSourcedGameRepository) RebuildProjection(gameID string) (*domain.Game, error) {
+	ctx := context.Background()
+
+	base, err := r.baseSnapshot(ctx, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := r.loadEvents(ctx, gameID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil && len(events) == 0 {
+		return nil, domain.ErrGameNotFound
+	}
+
+	game, err := domain.RebuildGame(gameID, base, events)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := json.Marshal(game.Board)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := json.Marshal(game.Players)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE games
+		SET board = $1, players = $2, moves = $3, timed_out_by = $4, status = $5, winner_id = $6, updated_at = $7, version = $8
+		WHERE id = $9
+	`
+	_, err = tx.Exec(ctx, query, board, players, moves, game.TimedOutBy, game.Status, winnerID(game), game.UpdatedAt, game.Version, game.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM game_snapshots WHERE game_id = $1 AND seq > 0`, gameID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) > 0 {
+		if err := saveSnapshot(ctx, tx, game, events[len(events)-1].Sequence); err != nil {
+			return nil, err
+		}
+	}
+
+	return game, tx.Commit(ctx)
+}
+
+func (r *EventSourcedGameRepository) appendEvents(ctx context.Context, tx pgx.Tx, game *domain.Game) error {
+	var lastSeq int
+	err := tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(seq), 0) FROM game_events WHERE game_id = $1`, game.ID,
+	).Scan(&lastSeq)
+	if err != nil {
+		return err
+	}
+
+	seq := lastSeq
+	for _, event := range game.PendingEvents() {
+		payload := eventPayload{
+			PlayerID:        event.PlayerID,
+			PlayerName:      event.PlayerName,
+			Symbol:          event.Symbol,
+			Rated:           event.Rated,
+			ChatID:          event.ChatID,
+			InlineMessageID: event.InlineMessageID,
+		}
+		if event.Type == domain.GameEventMoved {
+			coord := event.Coordinate
+			payload.Coordinate = &coord
+		}
+
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		seq++
+		query := `
+			INSERT INTO game_events (game_id, seq, version, type, payload, occurred_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`
+		_, err = tx.Exec(ctx, query, game.ID, seq, game.Version, event.Type, data, event.OccurredAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.snapshotInterval > 0 && seq/r.snapshotInterval > lastSeq/r.snapshotInterval {
+		return saveSnapshot(ctx, tx, game, seq)
+	}
+
+	return nil
+}
+
+func (r *EventSourcedGameRepository) latestSnapshot(ctx context.Context, gameID string) (*domain.Game, int, error) {
+	query := `
+		SELECT seq, state
+		FROM game_snapshots
+		WHERE game_id = $1
+		ORDER BY seq DESC
+		LIMIT 1
+	`
+	return r.loadSnapshot(ctx, query, gameID)
+}
+
+func (r *EventSourcedGameRepository) baseSnapshot(ctx context.Context, gameID string) (*domain.Game, error) {
+	query := `
+		SELECT seq, state
+		FROM game_snapshots
+		WHERE game_id = $1 AND seq = 0
+	`
+	game, _, err := r.loadSnapshot(ctx, query, gameID)
+	return game, err
+}
+
+func (r *EventSourcedGameRepository) loadSnapshot(ctx context.Context, query, gameID string) (*domain.Game, int, error) {
+	var seq int
+	var state []byte
+
+	err := r.db.QueryRow(ctx, query, gameID).Scan(&seq, &state)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var game domain.Game
+	if err := json.Unmarshal(state, &game); err != nil {
+		return nil, 0, err
+	}
+
+	return &game, seq, nil
+}
+
+func (r *EventSourcedGameRepository) loadEvents(ctx context.Context, gameID string, afterSeq int) ([]domain.GameEvent, error) {
+	query := `
+		SELECT seq, version, type, payload, occurred_at
+		FROM game_events
+		WHERE game_id = $1 AND seq > $2
+		ORDER BY seq
+	`
+
+	rows, err := r.db.Query(ctx, query, gameID, afterSeq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.GameEvent
+	for rows.Next() {
+		var event domain.GameEvent
+		var data []byte
+
+		err := rows.Scan(&event.Sequence, &event.Version, &event.Type, &data, &event.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		var payload eventPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, err
+		}
+
+		event.PlayerID = payload.PlayerID
+		event.PlayerName = payload.PlayerName
+		event.Symbol = payload.Symbol
+		event.Rated = payload.Rated
+		event.ChatID = payload.ChatID
+		event.InlineMessageID = payload.InlineMessageID
+		if payload.Coordinate != nil {
+			event.Coordinate = *payload.Coordinate
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func saveSnapshot(ctx context.Context, q querier, game *domain.Game, seq int) error {
+	state, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO game_snapshots (game_id, seq, state, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (game_id, seq) DO UPDATE SET state = EXCLUDED.state
+	`
+	_, err = q.Exec(ctx, query, game.ID, seq, state)
+	return err
+}
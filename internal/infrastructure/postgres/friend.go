@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type FriendRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFriendRepository(db *pgxpool.Pool) *FriendRepository {
+	return &FriendRepository{db: db}
+}
+
+func (r *FriendRepository) Add(userID, friendID string) error {
+	query := `
+		INSERT INTO friends (user_id, friend_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, friend_id) DO NOTHING
+	`
+	_, err := r.db.Exec(context.Background(), query, userID, friendID)
+	return err
+}
+
+func (r *FriendRepository) Remove(userID, friendID string) (bool, error) {
+	tag, err := r.db.Exec(context.Background(),
+		`DELETE FROM friends WHERE user_id = $1 AND friend_id = $2`, userID, friendID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *FriendRepository) List(userID string) ([]domain.Friend, error) {
+	query := `
+		SELECT u.id, u.display_name, COALESCE((
+			SELECT g.status
+			FROM games g
+			WHERE g.status IN ('active', 'waiting')
+				AND (g.players->0->>'ID' = u.id OR g.players->1->>'ID' = u.id)
+			ORDER BY g.status = 'active' DESC
+			LIMIT 1
+		), '')
+		FROM friends f
+		JOIN users u ON u.id = f.friend_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at
+	`
+
+	rows, err := r.db.Query(context.Background(), query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var friends []domain.Friend
+	for rows.Next() {
+		var f domain.Friend
+		if err := rows.Scan(&f.UserID, &f.DisplayName, &f.GameStatus); err != nil {
+			return nil, err
+		}
+		friends = append(friends, f)
+	}
+
+	return friends, rows.Err()
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type MatchmakingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMatchmakingRepository(db *pgxpool.Pool) *MatchmakingRepository {
+	return &MatchmakingRepository{db: db}
+}
+
+func (r *MatchmakingRepository) Enqueue(entry domain.QueueEntry) error {
+	query := `
+		INSERT INTO matchmaking_queue (user_id, user_name, rating, enqueued_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET user_name = EXCLUDED.user_name, rating = EXCLUDED.rating
+	`
+	_, err := r.db.Exec(context.Background(), query, entry.UserID, entry.UserName, entry.Rating, entry.EnqueuedAt)
+	return err
+}
+
+func (r *MatchmakingRepository) Remove(userID string) (bool, error) {
+	tag, err := r.db.Exec(context.Background(), `DELETE FROM matchmaking_queue WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *MatchmakingRepository) FindMatches(now time.Time) ([]domain.Match, error) {
+	ctx := context.Background()
+
+	rows, err := r.db.Query(ctx, `SELECT user_id, user_name, rating, enqueued_at FROM matchmaking_queue`)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []domain.QueueEntry
+	for rows.Next() {
+		var e domain.QueueEntry
+		if err := rows.Scan(&e.UserID, &e.UserName, &e.Rating, &e.EnqueuedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	blocks, err := queuedBlocks(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.PairQueue(entries, now, blocks), nil
+}
+
+// dequeueMatch убирает пару из очереди. Удаление блокирует строки игроков, поэтому параллельный
+// подбор той же пары дождётся этой транзакции и получит ErrMatchTaken, а не вторую игру.
+func dequeueMatch(ctx context.Context, q querier, match domain.Match) error {
+	tag, err := q.Exec(ctx, `DELETE FROM matchmaking_queue WHERE user_id IN ($1, $2)`, match.First.UserID, match.Second.UserID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 2 {
+		return domain.ErrMatchTaken
+	}
+	return nil
+}
+
+// leaveQueue убирает игроков игры из очереди быстрой игры.
+func leaveQueue(ctx context.Context, q querier, game *domain.Game) error {
+	_, err := q.Exec(ctx, `DELETE FROM matchmaking_queue WHERE user_id IN ($1, $2)`, game.Players[0].ID, game.Players[1].ID)
+	return err
+}
+
+func queuedBlocks(ctx context.Context, q querier) (domain.Blocks, error) {
+	query := `
+		SELECT user_id, blocked_id
+		FROM blocks
+		WHERE user_id IN (SELECT user_id FROM matchmaking_queue)
+			AND blocked_id IN (SELECT user_id FROM matchmaking_queue)
+	`
+
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := domain.Blocks{}
+	for rows.Next() {
+		var userID, blockedID string
+		if err := rows.Scan(&userID, &blockedID); err != nil {
+			return nil, err
+		}
+		blocks.Add(userID, blockedID)
+	}
+
+	return blocks, rows.Err()
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// FetchPending выбирает готовые к отправке уведомления и откладывает их на время lease,
+// чтобы параллельные диспетчеры не отправили одно уведомление дважды.
+func (r *NotificationRepository) FetchPending(limit int, lease time.Duration) ([]domain.Notification, error) {
+	query := `
+		UPDATE notification_outbox
+		SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id
+			FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= $3
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, payload, attempts, created_at
+	`
+
+	rows, err := r.db.Query(context.Background(), query, time.Now().Add(lease), limit, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Payload, &n.Attempts, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) MarkSent(id string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'sent', sent_at = NOW(), attempts = attempts + 1
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(context.Background(), query, id)
+	return err
+}
+
+func (r *NotificationRepository) MarkFailed(id string, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(context.Background(), query, id, nextAttemptAt, lastError)
+	return err
+}
+
+func (r *NotificationRepository) MarkDead(id string, lastError string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'failed', attempts = attempts + 1, last_error = $2
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(context.Background(), query, id, lastError)
+	return err
+}
+
+func insertNotifications(ctx context.Context, q querier, notifications []domain.Notification) error {
+	query := `
+		INSERT INTO notification_outbox (id, user_id, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, 'pending', 0, $4, $4)
+	`
+
+	for _, n := range notifications {
+		if _, err := q.Exec(ctx, query, n.ID, n.UserID, n.Payload, n.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type RatingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRatingRepository(db *pgxpool.Pool) *RatingRepository {
+	return &RatingRepository{db: db}
+}
+
+func (r *RatingRepository) GetChangesByGame(gameID string) ([]domain.RatingChange, error) {
+	query := `
+		SELECT ` + ratingChangeColumns + `
+		FROM rating_history
+		WHERE game_id = $1
+	`
+	return queryRatingChanges(r.db.Query(context.Background(), query, gameID))
+}
+
+func (r *RatingRepository) GetHistory(userID string, limit int) ([]domain.RatingChange, error) {
+	query := `
+		SELECT ` + ratingChangeColumns + `
+		FROM rating_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT NULLIF($2, 0)
+	`
+	return queryRatingChanges(r.db.Query(context.Background(), query, userID, limit))
+}
+
+const ratingChangeColumns = `game_id, user_id, rating_before, rating_after, delta, created_at`
+
+func queryRatingChanges(rows pgx.Rows, err error) ([]domain.RatingChange, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []domain.RatingChange
+	for rows.Next() {
+		var c domain.RatingChange
+		if err := rows.Scan(&c.GameID, &c.UserID, &c.Before, &c.After, &c.Delta, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// applyRatingChanges записывает историю и сдвигает рейтинг на дельту. Первичный ключ истории (game_id, user_id)
+// не даёт применить результат одной игры дважды, например при повторной обработке хода.
+func applyRatingChanges(ctx context.Context, q querier, changes []domain.RatingChange) error {
+	for _, c := range changes {
+		query := `
+			INSERT INTO rating_history (game_id, user_id, rating_before, rating_after, delta, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (game_id, user_id) DO NOTHING
+		`
+		tag, err := q.Exec(ctx, query, c.GameID, c.UserID, c.Before, c.After, c.Delta, c.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+
+		query = `
+			UPDATE users
+			SET rating = rating + $2, rated_games = rated_games + 1
+			WHERE id = $1
+		`
+		if _, err := q.Exec(ctx, query, c.UserID, c.Delta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
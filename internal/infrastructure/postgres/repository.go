@@ -3,20 +3,199 @@ package postgres
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/tictactoe/internal/domain"
 )
 
 type GameRepository struct {
-	db *pgx.Conn
+	db *pgxpool.Pool
 }
 
-func NewGameRepository(db *pgx.Conn) *GameRepository {
+func NewGameRepository(db *pgxpool.Pool) *GameRepository {
 	return &GameRepository{db: db}
 }
 
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 func (r *GameRepository) Create(game *domain.Game) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := leaveQueue(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(ctx, tx, game); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Start сохраняет начатую игру и закрывает то, из чего она создана, в одной транзакции.
+func (r *GameRepository) Start(game *domain.Game, origin domain.GameOrigin, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := closeOrigin(ctx, tx, origin); err != nil {
+		return err
+	}
+	if err := leaveQueue(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(ctx, tx, game); err != nil {
+		return err
+	}
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// closeOrigin закрывает запись, из которой создаётся игра.
+func closeOrigin(ctx context.Context, q querier, origin domain.GameOrigin) error {
+	if origin.Match != nil {
+		if err := dequeueMatch(ctx, q, *origin.Match); err != nil {
+			return err
+		}
+	}
+	if origin.Challenge != nil {
+		return resolveChallenge(ctx, q, origin.Challenge)
+	}
+	return nil
+}
+
+func (r *GameRepository) Join(game *domain.Game, notifications ...domain.Notification) error {
+	ctx := context.Background()
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = updateGame(ctx, tx, game)
+	if err == nil {
+		err = leaveQueue(ctx, tx, game)
+	}
+	if err == nil {
+		err = insertNotifications(ctx, tx, notifications)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	return nil
+}
+
+func (r *GameRepository) Update(game *domain.Game, notifications ...domain.Notification) error {
+	ctx := context.Background()
+	if len(notifications) == 0 {
+		return updateGame(ctx, r.db, game)
+	}
+
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateGame(ctx, tx, game); err != nil {
+		return err
+	}
+
+	err = insertNotifications(ctx, tx, notifications)
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	return nil
+}
+
+// Finish сохраняет завершённую игру, изменения рейтинга, награды и уведомления в одной транзакции.
+func (r *GameRepository) Finish(game *domain.Game, settle domain.SettleFunc) error {
+	ctx := context.Background()
+	version := game.Version
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = updateGame(ctx, tx, game)
+	if err == nil {
+		err = settleGame(ctx, tx, game, settle)
+	}
+	if err == nil {
+		err = tx.Commit(ctx)
+	}
+	if err != nil {
+		game.Version = version
+		return err
+	}
+
+	return nil
+}
+
+// settleGame засчитывает результат турнирной партии, читает профили игроков с блокировкой,
+// считает по ним итоги игры и записывает их.
+// Строку игры к этому моменту уже заблокировало её обновление, поэтому второй запрос с тем же
+// завершающим ходом получит ErrConcurrentUpdate раньше, чем дойдёт до рейтинга.
+func settleGame(ctx context.Context, q querier, game *domain.Game, settle domain.SettleFunc) error {
+	if _, err := recordTournamentResult(ctx, q, game.ID, gameWinnerID(game)); err != nil {
+		return err
+	}
+
+	users, err := lockUsers(ctx, q, []string{game.Players[0].ID, game.Players[1].ID})
+	if err != nil {
+		return err
+	}
+
+	settlement, err := settle(users, func(achievements []domain.UserAchievement) ([]domain.UserAchievement, error) {
+		return awardAchievements(ctx, q, achievements)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := applyRatingChanges(ctx, q, settlement.RatingChanges); err != nil {
+		return err
+	}
+	return insertNotifications(ctx, q, settlement.Notifications)
+}
+
+func insertGame(ctx context.Context, q querier, game *domain.Game) error {
 	board, err := json.Marshal(game.Board)
 	if err != nil {
 		return err
@@ -27,16 +206,21 @@ func (r *GameRepository) Create(game *domain.Game) error {
 		return err
 	}
 
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO games (id, board, players, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO games (id, board, players, status, rated, chat_id, inline_message_id, moves, timed_out_by, winner_id, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
-	_, err = r.db.Exec(context.Background(), query,
-		game.ID, board, players, game.Status, game.CreatedAt, game.UpdatedAt)
+	_, err = q.Exec(ctx, query,
+		game.ID, board, players, game.Status, game.Rated, game.ChatID, game.InlineMessageID, moves, game.TimedOutBy, winnerID(game), game.Version, game.CreatedAt, game.UpdatedAt)
 	return err
 }
 
-func (r *GameRepository) Update(game *domain.Game) error {
+func updateGame(ctx context.Context, q querier, game *domain.Game) error {
 	board, err := json.Marshal(game.Board)
 	if err != nil {
 		return err
@@ -47,107 +231,174 @@ func (r *GameRepository) Update(game *domain.Game) error {
 		return err
 	}
 
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE games
-		SET board = $1, players = $2, status = $3, updated_at = $4
-		WHERE id = $5
+		SET board = $1, players = $2, moves = $3, timed_out_by = $4, status = $5, winner_id = $6, updated_at = $7, version = version + 1
+		WHERE id = $8 AND version = $9
 	`
-	_, err = r.db.Exec(context.Background(), query,
-		board, players, game.Status, game.UpdatedAt, game.ID)
-	return err
+	tag, err := q.Exec(ctx, query,
+		board, players, moves, game.TimedOutBy, game.Status, winnerID(game), game.UpdatedAt, game.ID, game.Version)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConcurrentUpdate
+	}
+
+	game.Version++
+	return nil
+}
+
+// gameMoves возвращает ходы игры для колонки moves: пустой список вместо NULL.
+func gameMoves(game *domain.Game) []domain.Coordinate {
+	if game.Moves == nil {
+		return []domain.Coordinate{}
+	}
+	return game.Moves
+}
+
+// winnerID возвращает значение колонки winner_id: NULL, пока у игры нет победителя.
+func winnerID(game *domain.Game) *string {
+	if winner := game.Winner(); winner != nil {
+		return &winner.ID
+	}
+	return nil
+}
+
+// gameWinnerID возвращает победителя для результата турнирной партии: пустую строку при ничьей.
+func gameWinnerID(game *domain.Game) string {
+	if winner := game.Winner(); winner != nil {
+		return winner.ID
+	}
+	return ""
 }
 
+const gameColumns = `id, board, players, status, rated, chat_id, inline_message_id, moves, timed_out_by, version, created_at, updated_at`
+
 func (r *GameRepository) GetByID(id string) (*domain.Game, error) {
 	query := `
-		SELECT id, board, players, status, created_at, updated_at
+		SELECT ` + gameColumns + `
 		FROM games
 		WHERE id = $1
 	`
 
-	var game domain.Game
-	var boardJSON, playersJSON []byte
-
-	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&game.ID,
-		&boardJSON,
-		&playersJSON,
-		&game.Status,
-		&game.CreatedAt,
-		&game.UpdatedAt,
-	)
-	if err != nil {
-		return nil, err
+	game, err := scanGame(r.db.QueryRow(context.Background(), query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
 	}
+	return game, err
+}
 
-	err = json.Unmarshal(boardJSON, &game.Board)
-	if err != nil {
-		return nil, err
-	}
+func (r *GameRepository) GetByInlineMessageID(inlineMessageID string) (*domain.Game, error) {
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE inline_message_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
 
-	err = json.Unmarshal(playersJSON, &game.Players)
-	if err != nil {
-		return nil, err
+	game, err := scanGame(r.db.QueryRow(context.Background(), query, inlineMessageID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
 	}
-
-	return &game, nil
+	return game, err
 }
 
-func (r *GameRepository) GetAvailableGames() ([]*domain.Game, error) {
+func (r *GameRepository) GetAvailableGames(filter domain.GameFilter) (*domain.GamePage, error) {
+	order := "ASC"
+	if filter.NewestFirst {
+		order = "DESC"
+	}
+
 	query := `
-		SELECT id, board, players, status, created_at, updated_at
+		SELECT ` + gameColumns + `, COUNT(*) OVER ()
 		FROM games
-		WHERE status = 'waiting'
+		WHERE status = 'waiting' AND chat_id = '' AND inline_message_id = '' AND ($1 = '' OR players->0->>'ID' <> $1)
+			AND ($4 = '' OR NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.user_id = $4 AND b.blocked_id = players->0->>'ID')
+					OR (b.user_id = players->0->>'ID' AND b.blocked_id = $4)
+			))
+			AND ($5 = 0 OR COALESCE((SELECT u.rating FROM users u WHERE u.id = players->0->>'ID'), $7) >= $5)
+			AND ($6 = 0 OR COALESCE((SELECT u.rating FROM users u WHERE u.id = players->0->>'ID'), $7) <= $6)
+		ORDER BY created_at ` + order + `, id ` + order + `
+		LIMIT NULLIF($2, 0) OFFSET $3
 	`
 
-	rows, err := r.db.Query(context.Background(), query)
+	rows, err := r.db.Query(context.Background(), query,
+		filter.ExcludeCreatorID, filter.Limit, filter.Offset, filter.ViewerID,
+		filter.MinRating, filter.MaxRating, domain.DefaultRating)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var games []*domain.Game
+	page := &domain.GamePage{}
 	for rows.Next() {
-		var game domain.Game
-		var boardJSON, playersJSON []byte
-
-		err := rows.Scan(
-			&game.ID,
-			&boardJSON,
-			&playersJSON,
-			&game.Status,
-			&game.CreatedAt,
-			&game.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		err = json.Unmarshal(boardJSON, &game.Board)
-		if err != nil {
-			return nil, err
-		}
-
-		err = json.Unmarshal(playersJSON, &game.Players)
+		game, err := scanGame(rows, &page.Total)
 		if err != nil {
 			return nil, err
 		}
-
-		games = append(games, &game)
+		page.Games = append(page.Games, game)
 	}
 
-	return games, nil
+	return page, rows.Err()
 }
 
 func (r *GameRepository) GetActiveGamesByUser(userID string) ([]*domain.Game, error) {
 	query := `
-		SELECT id, board, players, status, created_at, updated_at
+		SELECT ` + gameColumns + `
 		FROM games
 		WHERE status = 'active' AND (
 			(players->0->>'ID' = $1) OR (players->1->>'ID' = $1)
 		)
 	`
 
-	rows, err := r.db.Query(context.Background(), query, userID)
+	return queryGames(context.Background(), r.db, query, userID)
+}
+
+func (r *GameRepository) GetFinishedGamesByUser(userID string) ([]domain.GameResult, error) {
+	ctx := context.Background()
+
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE status = 'finished' AND (
+			(players->0->>'ID' = $1) OR (players->1->>'ID' = $1)
+		)
+	`
+
+	games, err := queryGames(ctx, r.db, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []domain.GameResult
+	for _, game := range games {
+		results = append(results, game.Result())
+	}
+
+	archived, err := archivedResultsByUser(ctx, r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, archived...)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].FinishedAt.Before(results[j].FinishedAt)
+	})
+
+	return results, nil
+}
+
+func queryGames(ctx context.Context, q querier, query string, args ...any) ([]*domain.Game, error) {
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,33 +406,54 @@ func (r *GameRepository) GetActiveGamesByUser(userID string) ([]*domain.Game, er
 
 	var games []*domain.Game
 	for rows.Next() {
-		var game domain.Game
-		var boardJSON, playersJSON []byte
-
-		err := rows.Scan(
-			&game.ID,
-			&boardJSON,
-			&playersJSON,
-			&game.Status,
-			&game.CreatedAt,
-			&game.UpdatedAt,
-		)
+		game, err := scanGame(rows)
 		if err != nil {
 			return nil, err
 		}
+		games = append(games, game)
+	}
 
-		err = json.Unmarshal(boardJSON, &game.Board)
-		if err != nil {
-			return nil, err
-		}
+	return games, rows.Err()
+}
 
-		err = json.Unmarshal(playersJSON, &game.Players)
-		if err != nil {
-			return nil, err
-		}
+func scanGame(row pgx.Row, extra ...any) (*domain.Game, error) {
+	var game domain.Game
+	var boardJSON, playersJSON, movesJSON []byte
+
+	dest := []any{
+		&game.ID,
+		&boardJSON,
+		&playersJSON,
+		&game.Status,
+		&game.Rated,
+		&game.ChatID,
+		&game.InlineMessageID,
+		&movesJSON,
+		&game.TimedOutBy,
+		&game.Version,
+		&game.CreatedAt,
+		&game.UpdatedAt,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
 
-		games = append(games, &game)
+	err = json.Unmarshal(boardJSON, &game.Board)
+	if err != nil {
+		return nil, err
 	}
 
-	return games, nil
+	err = json.Unmarshal(playersJSON, &game.Players)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(movesJSON, &game.Moves)
+	if err != nil {
+		return nil, err
+	}
+
+	return &game, nil
 }
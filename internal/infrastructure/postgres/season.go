@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+const seasonColumns = `id, starts_at, ends_at, archived_at`
+
+type SeasonRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSeasonRepository(db *pgxpool.Pool) *SeasonRepository {
+	return &SeasonRepository{db: db}
+}
+
+func (r *SeasonRepository) Current() (*domain.Season, error) {
+	row := r.db.QueryRow(context.Background(),
+		`SELECT `+seasonColumns+` FROM seasons WHERE archived_at IS NULL ORDER BY id DESC LIMIT 1`)
+
+	s, err := scanSeason(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSeasonNotFound
+	}
+	return s, err
+}
+
+func (r *SeasonRepository) GetByID(id int) (*domain.Season, error) {
+	row := r.db.QueryRow(context.Background(), `SELECT `+seasonColumns+` FROM seasons WHERE id = $1`, id)
+
+	s, err := scanSeason(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrSeasonNotFound
+	}
+	return s, err
+}
+
+func (r *SeasonRepository) Create(s *domain.Season) error {
+	_, err := r.db.Exec(context.Background(), `INSERT INTO seasons (id, starts_at, ends_at) VALUES ($1, $2, $3)`,
+		s.ID, s.StartsAt, s.EndsAt)
+	return err
+}
+
+func (r *SeasonRepository) Rollover(finished, next *domain.Season, notify domain.SeasonNotifyFunc) ([]domain.SeasonStanding, error) {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE seasons SET archived_at = $1 WHERE id = $2 AND archived_at IS NULL`,
+		time.Now(), finished.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, domain.ErrSeasonClosed
+	}
+
+	// Блокировка ждёт партии, уже изменившие рейтинги, и не даёт завершиться новым до сброса:
+	// иначе таблица и сброшенные рейтинги разойдутся.
+	if _, err := tx.Exec(ctx, `LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	standings, err := finalStandings(ctx, tx, finished)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO season_standings (season_id, user_id, display_name, rank, rating)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, st := range standings {
+		if _, err := tx.Exec(ctx, query, st.SeasonID, st.UserID, st.DisplayName, st.Rank, st.Rating); err != nil {
+			return nil, err
+		}
+	}
+
+	query = `UPDATE users SET rating = $1 + (rating - $1) * $2 / 100`
+	if _, err := tx.Exec(ctx, query, domain.DefaultRating, domain.SeasonCarryOverPercent); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO seasons (id, starts_at, ends_at) VALUES ($1, $2, $3)`,
+		next.ID, next.StartsAt, next.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := notify(standings)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return nil, err
+	}
+
+	return standings, tx.Commit(ctx)
+}
+
+// finalStandings строит итоговую таблицу сезона: игроки, сыгравшие в нём рейтинговые игры, по рейтингу.
+func finalStandings(ctx context.Context, q querier, season *domain.Season) ([]domain.SeasonStanding, error) {
+	query := `
+		SELECT u.id, u.display_name, u.rating
+		FROM users u
+		WHERE EXISTS (SELECT 1 FROM rating_history h WHERE h.user_id = u.id AND h.created_at >= $1)
+		ORDER BY u.rating DESC, u.id
+	`
+	rows, err := q.Query(ctx, query, season.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []domain.SeasonStanding
+	for rows.Next() {
+		st := domain.SeasonStanding{SeasonID: season.ID, Rank: len(standings) + 1}
+		if err := rows.Scan(&st.UserID, &st.DisplayName, &st.Rating); err != nil {
+			return nil, err
+		}
+		standings = append(standings, st)
+	}
+
+	return standings, rows.Err()
+}
+
+func (r *SeasonRepository) GetStandings(seasonID, limit int) ([]domain.SeasonStanding, error) {
+	query := `
+		SELECT season_id, user_id, display_name, rank, rating
+		FROM season_standings
+		WHERE season_id = $1
+		ORDER BY rank
+		LIMIT NULLIF($2, 0)
+	`
+	return querySeasonStandings(r.db.Query(context.Background(), query, seasonID, limit))
+}
+
+func (r *SeasonRepository) GetUserStandings(userID string) ([]domain.SeasonStanding, error) {
+	query := `
+		SELECT season_id, user_id, display_name, rank, rating
+		FROM season_standings
+		WHERE user_id = $1
+		ORDER BY season_id DESC
+	`
+	return querySeasonStandings(r.db.Query(context.Background(), query, userID))
+}
+
+func querySeasonStandings(rows pgx.Rows, err error) ([]domain.SeasonStanding, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []domain.SeasonStanding
+	for rows.Next() {
+		var st domain.SeasonStanding
+		if err := rows.Scan(&st.SeasonID, &st.UserID, &st.DisplayName, &st.Rank, &st.Rating); err != nil {
+			return nil, err
+		}
+		standings = append(standings, st)
+	}
+
+	return standings, rows.Err()
+}
+
+func scanSeason(row pgx.Row) (*domain.Season, error) {
+	var s domain.Season
+	if err := row.Scan(&s.ID, &s.StartsAt, &s.EndsAt, &s.ArchivedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+const tournamentColumns = `id, name, format, status, organizer_id, organizer_name, starts_at, round, rounds, duration_seconds, created_at, finished_at`
+
+type TournamentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTournamentRepository(db *pgxpool.Pool) *TournamentRepository {
+	return &TournamentRepository{db: db}
+}
+
+func (r *TournamentRepository) Create(t *domain.Tournament) error {
+	query := `
+		INSERT INTO tournaments (id, name, format, status, organizer_id, organizer_name, starts_at, round, rounds, duration_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err := r.db.Exec(context.Background(), query,
+		t.ID, t.Name, t.Format, t.Status, t.OrganizerID, t.OrganizerName, t.StartsAt, t.Round, t.Rounds, int(t.Duration.Seconds()), t.CreatedAt)
+	return err
+}
+
+func (r *TournamentRepository) GetByID(id string) (*domain.Tournament, error) {
+	row := r.db.QueryRow(context.Background(), `SELECT `+tournamentColumns+` FROM tournaments WHERE id = $1`, id)
+
+	t, err := scanTournament(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTournamentNotFound
+	}
+	return t, err
+}
+
+func (r *TournamentRepository) ListByStatus(statuses ...domain.TournamentStatus) ([]*domain.Tournament, error) {
+	values := make([]string, len(statuses))
+	for i, status := range statuses {
+		values[i] = string(status)
+	}
+
+	query := `SELECT ` + tournamentColumns + ` FROM tournaments WHERE status = ANY($1) ORDER BY starts_at`
+	rows, err := r.db.Query(context.Background(), query, values)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []*domain.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, t)
+	}
+
+	return tournaments, rows.Err()
+}
+
+func (r *TournamentRepository) AddPlayer(p domain.TournamentPlayer, limit int) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status domain.TournamentStatus
+	err = tx.QueryRow(ctx, `SELECT status FROM tournaments WHERE id = $1 FOR UPDATE`, p.TournamentID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrTournamentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.TournamentRegistration {
+		return domain.ErrRegistrationClosed
+	}
+
+	var count int
+	var registered bool
+	query := `
+		SELECT COUNT(*), COALESCE(BOOL_OR(user_id = $1), FALSE)
+		FROM tournament_players
+		WHERE tournament_id = $2
+	`
+	if err := tx.QueryRow(ctx, query, p.UserID, p.TournamentID).Scan(&count, &registered); err != nil {
+		return err
+	}
+	if registered {
+		return domain.ErrAlreadyRegistered
+	}
+	if count >= limit {
+		return domain.ErrTournamentFull
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO tournament_players (tournament_id, user_id, user_name, seed, joined_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, p.TournamentID, p.UserID, p.UserName, p.Seed, p.JoinedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TournamentRepository) RemovePlayer(tournamentID, userID string) (bool, error) {
+	query := `
+		DELETE FROM tournament_players
+		WHERE tournament_id = $1 AND user_id = $2
+			AND EXISTS (SELECT 1 FROM tournaments WHERE id = $1 AND status = 'registration')
+	`
+	tag, err := r.db.Exec(context.Background(), query, tournamentID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *TournamentRepository) GetPlayers(tournamentID string) ([]domain.TournamentPlayer, error) {
+	query := `
+		SELECT tournament_id, user_id, user_name, seed, joined_at
+		FROM tournament_players
+		WHERE tournament_id = $1
+		ORDER BY seed, joined_at
+	`
+	rows, err := r.db.Query(context.Background(), query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []domain.TournamentPlayer
+	for rows.Next() {
+		var p domain.TournamentPlayer
+		if err := rows.Scan(&p.TournamentID, &p.UserID, &p.UserName, &p.Seed, &p.JoinedAt); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+
+	return players, rows.Err()
+}
+
+func (r *TournamentRepository) SetSeeds(players []domain.TournamentPlayer) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, p := range players {
+		_, err := tx.Exec(ctx, `UPDATE tournament_players SET seed = $1 WHERE tournament_id = $2 AND user_id = $3`,
+			p.Seed, p.TournamentID, p.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TournamentRepository) GetGames(tournamentID string) ([]domain.TournamentGame, error) {
+	query := `
+		SELECT id, tournament_id, round, COALESCE(game_id, ''), first_id, second_id, winner_id, finished, created_at
+		FROM tournament_games
+		WHERE tournament_id = $1
+		ORDER BY round, created_at, id
+	`
+	rows, err := r.db.Query(context.Background(), query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []domain.TournamentGame
+	for rows.Next() {
+		var g domain.TournamentGame
+		err := rows.Scan(&g.ID, &g.TournamentID, &g.Round, &g.GameID, &g.FirstID, &g.SecondID, &g.WinnerID, &g.Finished, &g.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+
+	return games, rows.Err()
+}
+
+func (r *TournamentRepository) StartRound(t *domain.Tournament, fromRound int, games []domain.TournamentGame, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE tournaments
+		SET status = $1, round = $2, rounds = $3
+		WHERE id = $4 AND round = $5
+	`, t.Status, t.Round, t.Rounds, t.ID, fromRound)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTournamentRoundDone
+	}
+
+	query := `
+		INSERT INTO tournament_games (id, tournament_id, round, game_id, first_id, second_id, winner_id, finished, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+	`
+	for _, g := range games {
+		_, err := tx.Exec(ctx, query,
+			g.ID, g.TournamentID, g.Round, g.GameID, g.FirstID, g.SecondID, g.WinnerID, g.Finished, g.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TournamentRepository) RecordResult(gameID, winnerID string) (string, error) {
+	ctx := context.Background()
+
+	tournamentID, err := recordTournamentResult(ctx, r.db, gameID, winnerID)
+	if err != nil || tournamentID != "" {
+		return tournamentID, err
+	}
+
+	err = r.db.QueryRow(ctx, `SELECT tournament_id FROM tournament_games WHERE game_id = $1`, gameID).Scan(&tournamentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return tournamentID, err
+}
+
+// recordTournamentResult отмечает результат ещё не засчитанной турнирной партии. Для обычной игры
+// или уже засчитанной партии возвращает пустую строку.
+func recordTournamentResult(ctx context.Context, q querier, gameID, winnerID string) (string, error) {
+	query := `
+		UPDATE tournament_games
+		SET winner_id = $1, finished = TRUE
+		WHERE game_id = $2 AND NOT finished
+		RETURNING tournament_id
+	`
+
+	var tournamentID string
+	err := q.QueryRow(ctx, query, winnerID, gameID).Scan(&tournamentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return tournamentID, err
+}
+
+func (r *TournamentRepository) Finish(t *domain.Tournament, notifications ...domain.Notification) error {
+	ctx := context.Background()
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE tournaments
+		SET status = $1, finished_at = $2
+		WHERE id = $3 AND status IN ('registration', 'running')
+	`, t.Status, t.FinishedAt, t.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTournamentRoundDone
+	}
+
+	if err := insertNotifications(ctx, tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanTournament(row pgx.Row) (*domain.Tournament, error) {
+	var t domain.Tournament
+	var duration int
+
+	err := row.Scan(&t.ID, &t.Name, &t.Format, &t.Status, &t.OrganizerID, &t.OrganizerName,
+		&t.StartsAt, &t.Round, &t.Rounds, &duration, &t.CreatedAt, &t.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Duration = time.Duration(duration) * time.Second
+	return &t, nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tictactoe/internal/domain"
+)
+
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Upsert(user *domain.User) error {
+	if user.Settings == nil {
+		user.Settings = map[string]string{}
+	}
+
+	settings, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO users (id, display_name, username, language, settings, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			display_name = COALESCE(NULLIF(EXCLUDED.display_name, ''), users.display_name),
+			username = COALESCE(NULLIF(EXCLUDED.username, ''), users.username),
+			language = COALESCE(NULLIF(EXCLUDED.language, ''), users.language),
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING ` + userColumns + `
+	`
+
+	saved, err := scanUser(r.db.QueryRow(context.Background(), query,
+		user.ID, user.DisplayName, user.Username, user.Language, settings, user.LastSeenAt,
+	))
+	if err != nil {
+		return err
+	}
+
+	*user = *saved
+	return nil
+}
+
+func (r *UserRepository) GetByID(id string) (*domain.User, error) {
+	users, err := r.GetByIDs([]string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	user, ok := users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *UserRepository) GetByUsername(username string) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE LOWER(username) = LOWER($1)
+		ORDER BY last_seen_at DESC
+		LIMIT 1
+	`
+	return scanUser(r.db.QueryRow(context.Background(), query, username))
+}
+
+func (r *UserRepository) GetByIDs(ids []string) (map[string]*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ANY($1)
+	`
+	return queryUsers(r.db.Query(context.Background(), query, ids))
+}
+
+// lockUsers читает профили с блокировкой строк до конца транзакции. Строки блокируются
+// в порядке id, чтобы встречные транзакции не взаимоблокировались.
+func lockUsers(ctx context.Context, q querier, ids []string) (map[string]*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ANY($1)
+		ORDER BY id
+		FOR UPDATE
+	`
+	return queryUsers(q.Query(ctx, query, ids))
+}
+
+func queryUsers(rows pgx.Rows, err error) (map[string]*domain.User, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := map[string]*domain.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+
+	return users, rows.Err()
+}
+
+const userColumns = `id, display_name, username, language, settings, created_at, last_seen_at, rating, rated_games`
+
+func scanUser(row pgx.Row) (*domain.User, error) {
+	var user domain.User
+	var settingsJSON []byte
+
+	err := row.Scan(
+		&user.ID,
+		&user.DisplayName,
+		&user.Username,
+		&user.Language,
+		&settingsJSON,
+		&user.CreatedAt,
+		&user.LastSeenAt,
+		&user.Rating,
+		&user.RatedGames,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(settingsJSON, &user.Settings); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type AchievementRepository struct {
+	db *sql.DB
+}
+
+func NewAchievementRepository(db *sql.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+func (r *AchievementRepository) GetByUser(userID string) ([]domain.UserAchievement, error) {
+	query := `
+		SELECT user_id, code, game_id, awarded_at
+		FROM user_achievements
+		WHERE user_id = ?
+		ORDER BY awarded_at
+	`
+	return queryAchievements(r.db.Query(query, userID))
+}
+
+func (r *AchievementRepository) GetByGame(gameID string) ([]domain.UserAchievement, error) {
+	query := `
+		SELECT user_id, code, game_id, awarded_at
+		FROM user_achievements
+		WHERE game_id = ?
+		ORDER BY awarded_at
+	`
+	return queryAchievements(r.db.Query(query, gameID))
+}
+
+func queryAchievements(rows *sql.Rows, err error) ([]domain.UserAchievement, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []domain.UserAchievement
+	for rows.Next() {
+		var a domain.UserAchievement
+		if err := rows.Scan(&a.UserID, &a.Code, &a.GameID, &a.AwardedAt); err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, a)
+	}
+
+	return achievements, rows.Err()
+}
+
+// awardAchievements сохраняет награды и возвращает выданные; уже полученные пользователем награды пропускаются.
+func awardAchievements(tx *sql.Tx, achievements []domain.UserAchievement) ([]domain.UserAchievement, error) {
+	query := `
+		INSERT INTO user_achievements (user_id, code, game_id, awarded_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, code) DO NOTHING
+	`
+	var awarded []domain.UserAchievement
+	for _, a := range achievements {
+		result, err := tx.Exec(query, a.UserID, a.Code, a.GameID, a.AwardedAt.UTC())
+		if err != nil {
+			return nil, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected > 0 {
+			awarded = append(awarded, a)
+		}
+	}
+
+	return awarded, nil
+}
@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type GameArchiveRepository struct {
+	db *sql.DB
+}
+
+func NewGameArchiveRepository(db *sql.DB) *GameArchiveRepository {
+	return &GameArchiveRepository{db: db}
+}
+
+func (r *GameArchiveRepository) ArchiveFinishedGames(before time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE status = 'finished' AND updated_at < ?1
+			AND (restored_at IS NULL OR restored_at < ?1)
+		ORDER BY updated_at
+		LIMIT ?2
+	`
+
+	rows, err := tx.Query(query, before.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var games []*domain.Game
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		games = append(games, game)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, game := range games {
+		if err := archiveGame(tx, game); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(games), nil
+}
+
+func (r *GameArchiveRepository) RestoreGame(id string) (*domain.Game, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var data []byte
+	err = tx.QueryRow(`SELECT data FROM games_archive WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	game, err := decompressGame(data)
+	if err != nil {
+		return nil, err
+	}
+
+	board, err := json.Marshal(game.Board)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := json.Marshal(game.Players)
+	if err != nil {
+		return nil, err
+	}
+
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO games (id, board, players, status, rated, chat_id, inline_message_id, moves, timed_out_by, winner_id, version, created_at, updated_at, restored_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.Exec(query,
+		game.ID, string(board), string(players), game.Status, game.Rated, game.ChatID, game.InlineMessageID, string(moves), game.TimedOutBy, winnerID(game), game.Version, game.CreatedAt.UTC(), game.UpdatedAt.UTC(), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM games_archive WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+
+	return game, tx.Commit()
+}
+
+func archiveGame(tx *sql.Tx, game *domain.Game) error {
+	data, err := compressGame(game)
+	if err != nil {
+		return err
+	}
+
+	result := game.Result()
+
+	var winnerID *string
+	if result.WinnerID != "" {
+		winnerID = &result.WinnerID
+	}
+
+	query := `
+		INSERT INTO games_archive (id, player_x_id, player_o_id, winner_id, moves, created_at, finished_at, archived_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.Exec(query,
+		game.ID, result.PlayerXID, result.PlayerOID, winnerID, result.Moves,
+		result.CreatedAt.UTC(), result.FinishedAt.UTC(), time.Now().UTC(), data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM games WHERE id = ?`, game.ID)
+	return err
+}
+
+func archivedResultsByUser(db *sql.DB, userID string) ([]domain.GameResult, error) {
+	query := `
+		SELECT id, player_x_id, player_o_id, COALESCE(winner_id, ''), COALESCE(moves, 0), created_at, finished_at
+		FROM games_archive
+		WHERE player_x_id = ?1 OR player_o_id = ?1
+	`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []domain.GameResult
+	for rows.Next() {
+		var r domain.GameResult
+		err := rows.Scan(&r.GameID, &r.PlayerXID, &r.PlayerOID, &r.WinnerID, &r.Moves, &r.CreatedAt, &r.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+
+	return results, rows.Err()
+}
+
+func compressGame(game *domain.Game) ([]byte, error) {
+	state, err := json.Marshal(game)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(state); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompressGame(data []byte) (*domain.Game, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	state, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var game domain.Game
+	if err := json.Unmarshal(state, &game); err != nil {
+		return nil, err
+	}
+
+	// архив мог быть записан до перехода на идентификаторы пользователей Telegram
+	for i := range game.Players {
+		game.Players[i].ID = domain.NormalizeUserID(game.Players[i].ID)
+	}
+
+	return &game, nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+type BlockRepository struct {
+	db *sql.DB
+}
+
+func NewBlockRepository(db *sql.DB) *BlockRepository {
+	return &BlockRepository{db: db}
+}
+
+func (r *BlockRepository) Block(userID, blockedID string) error {
+	query := `
+		INSERT INTO blocks (user_id, blocked_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id, blocked_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, userID, blockedID, time.Now().UTC())
+	return err
+}
+
+func (r *BlockRepository) Unblock(userID, blockedID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM blocks WHERE user_id = ? AND blocked_id = ?`, userID, blockedID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *BlockRepository) IsBlocked(userID, otherID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (user_id = ?1 AND blocked_id = ?2) OR (user_id = ?2 AND blocked_id = ?1)
+		)
+	`
+
+	var blocked bool
+	err := r.db.QueryRow(query, userID, otherID).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *BlockRepository) ListBlocked(userID string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT blocked_id FROM blocks WHERE user_id = ? ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type ChallengeRepository struct {
+	db *sql.DB
+}
+
+func NewChallengeRepository(db *sql.DB) *ChallengeRepository {
+	return &ChallengeRepository{db: db}
+}
+
+func (r *ChallengeRepository) Create(c *domain.Challenge, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO challenges (id, challenger_id, challenger_name, target_id, target_name, status, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.Exec(query,
+		c.ID, c.ChallengerID, c.ChallengerName, c.TargetID, c.TargetName, c.Status, c.CreatedAt.UTC(), c.ExpiresAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ChallengeRepository) GetByID(id string) (*domain.Challenge, error) {
+	query := `
+		SELECT id, challenger_id, challenger_name, target_id, target_name, status, COALESCE(game_id, ''), created_at, expires_at
+		FROM challenges
+		WHERE id = ?
+	`
+
+	var c domain.Challenge
+	err := r.db.QueryRow(query, id).Scan(
+		&c.ID, &c.ChallengerID, &c.ChallengerName, &c.TargetID, &c.TargetName, &c.Status, &c.GameID, &c.CreatedAt, &c.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrChallengeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *ChallengeRepository) Resolve(c *domain.Challenge, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := resolveChallenge(tx, c); err != nil {
+		return err
+	}
+
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// resolveChallenge сохраняет ответ на вызов, если вызов ещё ждёт ответа.
+func resolveChallenge(tx *sql.Tx, c *domain.Challenge) error {
+	query := `
+		UPDATE challenges
+		SET status = ?, game_id = NULLIF(?, '')
+		WHERE id = ? AND status = 'pending'
+	`
+	result, err := tx.Exec(query, c.Status, c.GameID, c.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrChallengeClosed
+	}
+	return nil
+}
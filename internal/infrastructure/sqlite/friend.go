@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type FriendRepository struct {
+	db *sql.DB
+}
+
+func NewFriendRepository(db *sql.DB) *FriendRepository {
+	return &FriendRepository{db: db}
+}
+
+func (r *FriendRepository) Add(userID, friendID string) error {
+	query := `
+		INSERT INTO friends (user_id, friend_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id, friend_id) DO NOTHING
+	`
+	_, err := r.db.Exec(query, userID, friendID, time.Now().UTC())
+	return err
+}
+
+func (r *FriendRepository) Remove(userID, friendID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM friends WHERE user_id = ? AND friend_id = ?`, userID, friendID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *FriendRepository) List(userID string) ([]domain.Friend, error) {
+	query := `
+		SELECT u.id, u.display_name, COALESCE((
+			SELECT g.status
+			FROM games g
+			WHERE g.status IN ('active', 'waiting')
+				AND (json_extract(g.players, '$[0].ID') = u.id OR json_extract(g.players, '$[1].ID') = u.id)
+			ORDER BY g.status = 'active' DESC
+			LIMIT 1
+		), '')
+		FROM friends f
+		JOIN users u ON u.id = f.friend_id
+		WHERE f.user_id = ?
+		ORDER BY f.created_at
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var friends []domain.Friend
+	for rows.Next() {
+		var f domain.Friend
+		if err := rows.Scan(&f.UserID, &f.DisplayName, &f.GameStatus); err != nil {
+			return nil, err
+		}
+		friends = append(friends, f)
+	}
+
+	return friends, rows.Err()
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type LeaderboardRepository struct {
+	db *sql.DB
+}
+
+func NewLeaderboardRepository(db *sql.DB) *LeaderboardRepository {
+	return &LeaderboardRepository{db: db}
+}
+
+func (r *LeaderboardRepository) GetLeaderboard(filter domain.LeaderboardFilter) ([]domain.LeaderboardEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `
+		SELECT u.id, u.display_name, u.rating, 0
+		FROM users u
+		WHERE EXISTS (SELECT 1 FROM rating_history h WHERE h.user_id = u.id AND h.created_at >= ?1)
+			AND (?2 = '' OR EXISTS (SELECT 1 FROM chat_members m WHERE m.chat_id = ?2 AND m.user_id = u.id))
+		ORDER BY u.rating DESC, u.id
+		LIMIT ?3
+	`
+	if filter.Metric == domain.LeaderboardByWins {
+		query = `
+			WITH wins AS (
+				SELECT winner_id AS user_id
+				FROM games
+				WHERE status = 'finished' AND winner_id IS NOT NULL AND updated_at >= ?1
+				UNION ALL
+				SELECT winner_id
+				FROM games_archive
+				WHERE winner_id IS NOT NULL AND finished_at >= ?1
+			)
+			SELECT u.id, u.display_name, u.rating, COUNT(*) AS wins
+			FROM wins w
+			JOIN users u ON u.id = w.user_id
+			WHERE ?2 = '' OR EXISTS (SELECT 1 FROM chat_members m WHERE m.chat_id = ?2 AND m.user_id = u.id)
+			GROUP BY u.id
+			ORDER BY wins DESC, u.rating DESC, u.id
+			LIMIT ?3
+		`
+	}
+
+	rows, err := r.db.Query(query, filter.Since.UTC(), filter.ChatID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.LeaderboardEntry
+	for rows.Next() {
+		var e domain.LeaderboardEntry
+		if err := rows.Scan(&e.UserID, &e.DisplayName, &e.Rating, &e.Wins); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *LeaderboardRepository) AddChatMember(chatID, userID string) error {
+	query := `
+		INSERT INTO chat_members (chat_id, user_id, last_played_at)
+		VALUES (?1, ?2, ?3)
+		ON CONFLICT (chat_id, user_id) DO UPDATE SET last_played_at = excluded.last_played_at
+	`
+	_, err := r.db.Exec(query, chatID, userID, time.Now().UTC())
+	return err
+}
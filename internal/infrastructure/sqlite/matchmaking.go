@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type MatchmakingRepository struct {
+	db *sql.DB
+}
+
+func NewMatchmakingRepository(db *sql.DB) *MatchmakingRepository {
+	return &MatchmakingRepository{db: db}
+}
+
+func (r *MatchmakingRepository) Enqueue(entry domain.QueueEntry) error {
+	query := `
+		INSERT INTO matchmaking_queue (user_id, user_name, rating, enqueued_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET user_name = excluded.user_name, rating = excluded.rating
+	`
+	_, err := r.db.Exec(query, entry.UserID, entry.UserName, entry.Rating, entry.EnqueuedAt.UTC())
+	return err
+}
+
+func (r *MatchmakingRepository) Remove(userID string) (bool, error) {
+	result, err := r.db.Exec(`DELETE FROM matchmaking_queue WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *MatchmakingRepository) FindMatches(now time.Time) ([]domain.Match, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT user_id, user_name, rating, enqueued_at FROM matchmaking_queue`)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []domain.QueueEntry
+	for rows.Next() {
+		var e domain.QueueEntry
+		if err := rows.Scan(&e.UserID, &e.UserName, &e.Rating, &e.EnqueuedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	blocks, err := queuedBlocks(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.PairQueue(entries, now, blocks), nil
+}
+
+// dequeueMatch убирает пару из очереди. Если кого-то из игроков там уже нет - его забрал параллельный
+// подбор или он отменил поиск, - возвращает ErrMatchTaken.
+func dequeueMatch(tx *sql.Tx, match domain.Match) error {
+	result, err := tx.Exec(`DELETE FROM matchmaking_queue WHERE user_id IN (?, ?)`, match.First.UserID, match.Second.UserID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected != 2 {
+		return domain.ErrMatchTaken
+	}
+	return nil
+}
+
+// leaveQueue убирает игроков игры из очереди быстрой игры.
+func leaveQueue(tx *sql.Tx, game *domain.Game) error {
+	_, err := tx.Exec(`DELETE FROM matchmaking_queue WHERE user_id IN (?, ?)`, game.Players[0].ID, game.Players[1].ID)
+	return err
+}
+
+func queuedBlocks(tx *sql.Tx) (domain.Blocks, error) {
+	query := `
+		SELECT user_id, blocked_id
+		FROM blocks
+		WHERE user_id IN (SELECT user_id FROM matchmaking_queue)
+			AND blocked_id IN (SELECT user_id FROM matchmaking_queue)
+	`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := domain.Blocks{}
+	for rows.Next() {
+		var userID, blockedID string
+		if err := rows.Scan(&userID, &blockedID); err != nil {
+			return nil, err
+		}
+		blocks.Add(userID, blockedID)
+	}
+
+	return blocks, rows.Err()
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func Migrate(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var applied int
+		err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE name = ?`, name).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		content, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(db, name, upSection(string(content))); err != nil {
+			return fmt.Errorf("миграция %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, name, script string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)`, name, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upSection(content string) string {
+	if i := strings.Index(content, "-- +goose Down"); i >= 0 {
+		content = content[:i]
+	}
+	return strings.Replace(content, "-- +goose Up", "", 1)
+}
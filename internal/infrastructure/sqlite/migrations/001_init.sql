@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE games (
+    id TEXT PRIMARY KEY,
+    board TEXT NOT NULL,
+    players TEXT NOT NULL,
+    status TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_games_status ON games(status);
+
+-- +goose Down
+DROP TABLE games;
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX idx_games_status_created_at ON games(status, created_at, id);
+
+-- +goose Down
+DROP INDEX idx_games_status_created_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE games ADD COLUMN restored_at TIMESTAMP;
+
+CREATE TABLE games_archive (
+    id TEXT PRIMARY KEY,
+    player_x_id TEXT NOT NULL,
+    player_o_id TEXT NOT NULL,
+    winner_id TEXT,
+    created_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL,
+    data BLOB NOT NULL
+);
+
+CREATE INDEX idx_games_archive_player_x ON games_archive(player_x_id);
+CREATE INDEX idx_games_archive_player_o ON games_archive(player_o_id);
+CREATE INDEX idx_games_archive_finished_at ON games_archive(finished_at);
+
+-- +goose Down
+DROP TABLE games_archive;
+ALTER TABLE games DROP COLUMN restored_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE notification_outbox (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX idx_notification_outbox_pending ON notification_outbox(status, next_attempt_at);
+
+-- +goose Down
+DROP TABLE notification_outbox;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    display_name TEXT NOT NULL DEFAULT '',
+    username TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL DEFAULT '',
+    settings TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_users_username ON users(username COLLATE NOCASE);
+
+INSERT INTO users (id, display_name, created_at, last_seen_at)
+SELECT json_extract(p.value, '$.ID'), MAX(json_extract(p.value, '$.Name')), MIN(g.created_at), MAX(g.updated_at)
+FROM games g, json_each(g.players) p
+WHERE COALESCE(json_extract(p.value, '$.ID'), '') <> ''
+GROUP BY json_extract(p.value, '$.ID');
+
+-- +goose Down
+DROP TABLE users;
@@ -0,0 +1,40 @@
+-- +goose Up
+ALTER TABLE games ADD COLUMN winner_id TEXT;
+
+UPDATE games AS g
+SET winner_id = (
+    SELECT json_extract(p.value, '$.ID')
+    FROM json_each(g.players) p
+    WHERE json_extract(p.value, '$.Symbol') = (
+        SELECT l.column1
+        FROM (VALUES
+            (json_extract(g.board, '$[0][0]'), json_extract(g.board, '$[0][1]'), json_extract(g.board, '$[0][2]')),
+            (json_extract(g.board, '$[1][0]'), json_extract(g.board, '$[1][1]'), json_extract(g.board, '$[1][2]')),
+            (json_extract(g.board, '$[2][0]'), json_extract(g.board, '$[2][1]'), json_extract(g.board, '$[2][2]')),
+            (json_extract(g.board, '$[0][0]'), json_extract(g.board, '$[1][0]'), json_extract(g.board, '$[2][0]')),
+            (json_extract(g.board, '$[0][1]'), json_extract(g.board, '$[1][1]'), json_extract(g.board, '$[2][1]')),
+            (json_extract(g.board, '$[0][2]'), json_extract(g.board, '$[1][2]'), json_extract(g.board, '$[2][2]')),
+            (json_extract(g.board, '$[0][0]'), json_extract(g.board, '$[1][1]'), json_extract(g.board, '$[2][2]')),
+            (json_extract(g.board, '$[0][2]'), json_extract(g.board, '$[1][1]'), json_extract(g.board, '$[2][0]'))
+        ) AS l
+        WHERE l.column1 <> '' AND l.column1 = l.column2 AND l.column2 = l.column3
+        LIMIT 1
+    )
+)
+WHERE g.status = 'finished';
+
+CREATE INDEX idx_games_finished_winner ON games(winner_id, updated_at) WHERE status = 'finished';
+CREATE INDEX idx_games_archive_winner ON games_archive(winner_id, finished_at);
+
+CREATE TABLE chat_members (
+    chat_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    last_played_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE chat_members;
+DROP INDEX idx_games_archive_winner;
+DROP INDEX idx_games_finished_winner;
+ALTER TABLE games DROP COLUMN winner_id;
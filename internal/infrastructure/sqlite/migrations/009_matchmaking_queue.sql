@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE matchmaking_queue (
+    user_id TEXT PRIMARY KEY,
+    user_name TEXT NOT NULL DEFAULT '',
+    rating INTEGER NOT NULL,
+    enqueued_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE matchmaking_queue;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE challenges (
+    id TEXT PRIMARY KEY,
+    challenger_id TEXT NOT NULL,
+    challenger_name TEXT NOT NULL DEFAULT '',
+    target_id TEXT NOT NULL,
+    target_name TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    game_id TEXT,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_challenges_target ON challenges(target_id, status);
+
+-- +goose Down
+DROP TABLE challenges;
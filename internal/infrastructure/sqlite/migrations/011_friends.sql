@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE friends (
+    user_id TEXT NOT NULL,
+    friend_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, friend_id)
+);
+
+-- +goose Down
+DROP TABLE friends;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE blocks (
+    user_id TEXT NOT NULL,
+    blocked_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, blocked_id)
+);
+
+CREATE INDEX idx_blocks_blocked ON blocks(blocked_id);
+
+-- +goose Down
+DROP TABLE blocks;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE user_achievements (
+    user_id TEXT NOT NULL,
+    code TEXT NOT NULL,
+    game_id TEXT NOT NULL,
+    awarded_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, code)
+);
+
+CREATE INDEX idx_user_achievements_game ON user_achievements(game_id);
+
+-- +goose Down
+DROP TABLE user_achievements;
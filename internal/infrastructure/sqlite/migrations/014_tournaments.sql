@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE tournaments (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    format TEXT NOT NULL,
+    status TEXT NOT NULL,
+    organizer_id TEXT NOT NULL,
+    organizer_name TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP NOT NULL,
+    round INTEGER NOT NULL DEFAULT 0,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_tournaments_status ON tournaments(status, starts_at);
+
+CREATE TABLE tournament_players (
+    tournament_id TEXT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    user_name TEXT NOT NULL DEFAULT '',
+    seed INTEGER NOT NULL DEFAULT 0,
+    joined_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+CREATE TABLE tournament_games (
+    id TEXT PRIMARY KEY,
+    tournament_id TEXT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    game_id TEXT UNIQUE,
+    first_id TEXT NOT NULL,
+    second_id TEXT NOT NULL DEFAULT '',
+    winner_id TEXT NOT NULL DEFAULT '',
+    finished BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_tournament_games_tournament ON tournament_games(tournament_id, round);
+
+-- +goose Down
+DROP TABLE tournament_games;
+DROP TABLE tournament_players;
+DROP TABLE tournaments;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE tournaments ADD COLUMN duration_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE tournaments DROP COLUMN duration_seconds;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE seasons (
+    id INTEGER PRIMARY KEY,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP
+);
+
+CREATE TABLE season_standings (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    display_name TEXT NOT NULL DEFAULT '',
+    rank INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+    PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX idx_season_standings_user ON season_standings(user_id);
+
+-- +goose Down
+DROP TABLE season_standings;
+DROP TABLE seasons;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- Групповая игра: публичная доска игры живёт в чате, где игру создали.
+ALTER TABLE games ADD COLUMN chat_id TEXT NOT NULL DEFAULT '';
+
+-- Раньше пользователем считался чат (chat_<id>), теперь - пользователь Telegram (user_<id>).
+-- Для личных чатов идентификатор чата совпадает с идентификатором пользователя.
+UPDATE users SET id = 'user_' || SUBSTR(id, 6) WHERE SUBSTR(id, 1, 5) = 'chat_';
+UPDATE rating_history SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE chat_members SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE matchmaking_queue SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE challenges SET challenger_id = 'user_' || SUBSTR(challenger_id, 6) WHERE SUBSTR(challenger_id, 1, 5) = 'chat_';
+UPDATE challenges SET target_id = 'user_' || SUBSTR(target_id, 6) WHERE SUBSTR(target_id, 1, 5) = 'chat_';
+UPDATE friends SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE friends SET friend_id = 'user_' || SUBSTR(friend_id, 6) WHERE SUBSTR(friend_id, 1, 5) = 'chat_';
+UPDATE blocks SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE blocks SET blocked_id = 'user_' || SUBSTR(blocked_id, 6) WHERE SUBSTR(blocked_id, 1, 5) = 'chat_';
+UPDATE user_achievements SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE tournaments SET organizer_id = 'user_' || SUBSTR(organizer_id, 6) WHERE SUBSTR(organizer_id, 1, 5) = 'chat_';
+UPDATE tournament_players SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE tournament_games SET first_id = 'user_' || SUBSTR(first_id, 6) WHERE SUBSTR(first_id, 1, 5) = 'chat_';
+UPDATE tournament_games SET second_id = 'user_' || SUBSTR(second_id, 6) WHERE SUBSTR(second_id, 1, 5) = 'chat_';
+UPDATE tournament_games SET winner_id = 'user_' || SUBSTR(winner_id, 6) WHERE SUBSTR(winner_id, 1, 5) = 'chat_';
+UPDATE season_standings SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE games SET winner_id = 'user_' || SUBSTR(winner_id, 6) WHERE SUBSTR(winner_id, 1, 5) = 'chat_';
+UPDATE games_archive SET player_x_id = 'user_' || SUBSTR(player_x_id, 6) WHERE SUBSTR(player_x_id, 1, 5) = 'chat_';
+UPDATE games_archive SET player_o_id = 'user_' || SUBSTR(player_o_id, 6) WHERE SUBSTR(player_o_id, 1, 5) = 'chat_';
+UPDATE games_archive SET winner_id = 'user_' || SUBSTR(winner_id, 6) WHERE SUBSTR(winner_id, 1, 5) = 'chat_';
+UPDATE notification_outbox SET user_id = 'user_' || SUBSTR(user_id, 6) WHERE SUBSTR(user_id, 1, 5) = 'chat_';
+UPDATE games SET players = REPLACE(players, '"chat_', '"user_') WHERE players LIKE '%"chat!_%' ESCAPE '!';
+UPDATE notification_outbox SET payload = REPLACE(payload, '"chat_', '"user_') WHERE payload LIKE '%"chat!_%' ESCAPE '!';
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN chat_id;
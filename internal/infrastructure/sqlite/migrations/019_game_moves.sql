@@ -0,0 +1,6 @@
+-- +goose Up
+-- Порядок ходов партии: по доске его не восстановить, а он нужен, например, для награды за вилку.
+ALTER TABLE games ADD COLUMN moves TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN moves;
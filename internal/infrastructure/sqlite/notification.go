@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) FetchPending(limit int, lease time.Duration) ([]domain.Notification, error) {
+	now := time.Now().UTC()
+
+	query := `
+		UPDATE notification_outbox
+		SET next_attempt_at = ?1
+		WHERE id IN (
+			SELECT id
+			FROM notification_outbox
+			WHERE status = 'pending' AND next_attempt_at <= ?2
+			ORDER BY created_at
+			LIMIT ?3
+		)
+		RETURNING id, user_id, payload, attempts, created_at
+	`
+
+	rows, err := r.db.Query(query, now.Add(lease), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var payload string
+		if err := rows.Scan(&n.ID, &n.UserID, &payload, &n.Attempts, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.Payload = []byte(payload)
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) MarkSent(id string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'sent', sent_at = ?, attempts = attempts + 1
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(query, time.Now().UTC(), id)
+	return err
+}
+
+func (r *NotificationRepository) MarkFailed(id string, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE notification_outbox
+		SET attempts = attempts + 1, next_attempt_at = ?, last_error = ?
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(query, nextAttemptAt.UTC(), lastError, id)
+	return err
+}
+
+func (r *NotificationRepository) MarkDead(id string, lastError string) error {
+	query := `
+		UPDATE notification_outbox
+		SET status = 'failed', attempts = attempts + 1, last_error = ?
+		WHERE id = ?
+	`
+	_, err := r.db.Exec(query, lastError, id)
+	return err
+}
+
+func insertNotifications(tx *sql.Tx, notifications []domain.Notification) error {
+	query := `
+		INSERT INTO notification_outbox (id, user_id, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?1, ?2, ?3, 'pending', 0, ?4, ?4)
+	`
+
+	for _, n := range notifications {
+		if _, err := tx.Exec(query, n.ID, n.UserID, string(n.Payload), n.CreatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type RatingRepository struct {
+	db *sql.DB
+}
+
+func NewRatingRepository(db *sql.DB) *RatingRepository {
+	return &RatingRepository{db: db}
+}
+
+func (r *RatingRepository) GetChangesByGame(gameID string) ([]domain.RatingChange, error) {
+	query := `
+		SELECT ` + ratingChangeColumns + `
+		FROM rating_history
+		WHERE game_id = ?
+	`
+	return queryRatingChanges(r.db.Query(query, gameID))
+}
+
+func (r *RatingRepository) GetHistory(userID string, limit int) ([]domain.RatingChange, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `
+		SELECT ` + ratingChangeColumns + `
+		FROM rating_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`
+	return queryRatingChanges(r.db.Query(query, userID, limit))
+}
+
+const ratingChangeColumns = `game_id, user_id, rating_before, rating_after, delta, created_at`
+
+func queryRatingChanges(rows *sql.Rows, err error) ([]domain.RatingChange, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []domain.RatingChange
+	for rows.Next() {
+		var c domain.RatingChange
+		if err := rows.Scan(&c.GameID, &c.UserID, &c.Before, &c.After, &c.Delta, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// applyRatingChanges записывает историю и сдвигает рейтинг на дельту. Первичный ключ истории (game_id, user_id)
+// не даёт применить результат одной игры дважды.
+func applyRatingChanges(tx *sql.Tx, changes []domain.RatingChange) error {
+	for _, c := range changes {
+		query := `
+			INSERT INTO rating_history (game_id, user_id, rating_before, rating_after, delta, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (game_id, user_id) DO NOTHING
+		`
+		result, err := tx.Exec(query, c.GameID, c.UserID, c.Before, c.After, c.Delta, c.CreatedAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			continue
+		}
+
+		query = `
+			UPDATE users
+			SET rating = rating + ?2, rated_games = rated_games + 1
+			WHERE id = ?1
+		`
+		if _, err := tx.Exec(query, c.UserID, c.Delta); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
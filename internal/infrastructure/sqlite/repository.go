@@ -0,0 +1,441 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type GameRepository struct {
+	db *sql.DB
+}
+
+func NewGameRepository(db *sql.DB) *GameRepository {
+	return &GameRepository{db: db}
+}
+
+func (r *GameRepository) Create(game *domain.Game) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := leaveQueue(tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(tx.Exec, game); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Start сохраняет начатую игру и закрывает то, из чего она создана, в одной транзакции.
+func (r *GameRepository) Start(game *domain.Game, origin domain.GameOrigin, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := closeOrigin(tx, origin); err != nil {
+		return err
+	}
+	if err := leaveQueue(tx, game); err != nil {
+		return err
+	}
+	if err := insertGame(tx.Exec, game); err != nil {
+		return err
+	}
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// closeOrigin закрывает запись, из которой создаётся игра.
+func closeOrigin(tx *sql.Tx, origin domain.GameOrigin) error {
+	if origin.Match != nil {
+		if err := dequeueMatch(tx, *origin.Match); err != nil {
+			return err
+		}
+	}
+	if origin.Challenge != nil {
+		return resolveChallenge(tx, origin.Challenge)
+	}
+	return nil
+}
+
+// insertGame записывает новую игру через exec - запрос к базе или к открытой транзакции.
+func insertGame(exec func(query string, args ...any) (sql.Result, error), game *domain.Game) error {
+	board, err := json.Marshal(game.Board)
+	if err != nil {
+		return err
+	}
+
+	players, err := json.Marshal(game.Players)
+	if err != nil {
+		return err
+	}
+
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO games (id, board, players, status, rated, chat_id, inline_message_id, moves, timed_out_by, winner_id, version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = exec(query,
+		game.ID, string(board), string(players), game.Status, game.Rated, game.ChatID, game.InlineMessageID, string(moves), game.TimedOutBy, winnerID(game), game.Version, game.CreatedAt.UTC(), game.UpdatedAt.UTC())
+	return err
+}
+
+func (r *GameRepository) Join(game *domain.Game, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateGame(tx, game); err != nil {
+		return err
+	}
+	if err := leaveQueue(tx, game); err != nil {
+		return err
+	}
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	game.Version++
+	return nil
+}
+
+func (r *GameRepository) Update(game *domain.Game, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateGame(tx, game); err != nil {
+		return err
+	}
+
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	game.Version++
+	return nil
+}
+
+// updateGame записывает состояние игры, если с момента чтения её никто не изменил.
+func updateGame(tx *sql.Tx, game *domain.Game) error {
+	board, err := json.Marshal(game.Board)
+	if err != nil {
+		return err
+	}
+
+	players, err := json.Marshal(game.Players)
+	if err != nil {
+		return err
+	}
+
+	moves, err := json.Marshal(gameMoves(game))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE games
+		SET board = ?, players = ?, moves = ?, timed_out_by = ?, status = ?, winner_id = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
+	`
+	result, err := tx.Exec(query,
+		string(board), string(players), string(moves), game.TimedOutBy, game.Status, winnerID(game), game.UpdatedAt.UTC(), game.ID, game.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrConcurrentUpdate
+	}
+	return nil
+}
+
+// Finish сохраняет завершённую игру, результат турнирной партии, изменения рейтинга, награды
+// и уведомления в одной транзакции.
+// Соединение с SQLite одно, поэтому транзакции выполняются по очереди и профили игроков,
+// прочитанные внутри неё, не изменятся до фиксации.
+func (r *GameRepository) Finish(game *domain.Game, settle domain.SettleFunc) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateGame(tx, game); err != nil {
+		return err
+	}
+	if _, err := recordTournamentResult(tx.QueryRow, game.ID, gameWinnerID(game)); err != nil {
+		return err
+	}
+
+	users, err := getUsersByIDs(tx.Query, []string{game.Players[0].ID, game.Players[1].ID})
+	if err != nil {
+		return err
+	}
+
+	settlement, err := settle(users, func(achievements []domain.UserAchievement) ([]domain.UserAchievement, error) {
+		return awardAchievements(tx, achievements)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := applyRatingChanges(tx, settlement.RatingChanges); err != nil {
+		return err
+	}
+	if err := insertNotifications(tx, settlement.Notifications); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	game.Version++
+	return nil
+}
+
+func (r *GameRepository) GetByID(id string) (*domain.Game, error) {
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE id = ?
+	`
+
+	game, err := scanGame(r.db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	return game, err
+}
+
+func (r *GameRepository) GetByInlineMessageID(inlineMessageID string) (*domain.Game, error) {
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE inline_message_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	game, err := scanGame(r.db.QueryRow(query, inlineMessageID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrGameNotFound
+	}
+	return game, err
+}
+
+func (r *GameRepository) GetAvailableGames(filter domain.GameFilter) (*domain.GamePage, error) {
+	order := "ASC"
+	if filter.NewestFirst {
+		order = "DESC"
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `
+		SELECT ` + gameColumns + `, COUNT(*) OVER ()
+		FROM games
+		WHERE status = 'waiting' AND chat_id = '' AND inline_message_id = '' AND (?1 = '' OR json_extract(players, '$[0].ID') <> ?1)
+			AND (?4 = '' OR NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.user_id = ?4 AND b.blocked_id = json_extract(players, '$[0].ID'))
+					OR (b.user_id = json_extract(players, '$[0].ID') AND b.blocked_id = ?4)
+			))
+			AND (?5 = 0 OR COALESCE((SELECT u.rating FROM users u WHERE u.id = json_extract(players, '$[0].ID')), ?7) >= ?5)
+			AND (?6 = 0 OR COALESCE((SELECT u.rating FROM users u WHERE u.id = json_extract(players, '$[0].ID')), ?7) <= ?6)
+		ORDER BY created_at ` + order + `, id ` + order + `
+		LIMIT ?2 OFFSET ?3
+	`
+
+	rows, err := r.db.Query(query, filter.ExcludeCreatorID, limit, filter.Offset, filter.ViewerID,
+		filter.MinRating, filter.MaxRating, domain.DefaultRating)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &domain.GamePage{}
+	for rows.Next() {
+		var total int
+		game, err := scanGame(rows, &total)
+		if err != nil {
+			return nil, err
+		}
+		page.Games = append(page.Games, game)
+		page.Total = total
+	}
+
+	return page, rows.Err()
+}
+
+func (r *GameRepository) GetActiveGamesByUser(userID string) ([]*domain.Game, error) {
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE status = 'active' AND (
+			json_extract(players, '$[0].ID') = ?1 OR json_extract(players, '$[1].ID') = ?1
+		)
+	`
+
+	return r.queryGames(query, userID)
+}
+
+func (r *GameRepository) GetFinishedGamesByUser(userID string) ([]domain.GameResult, error) {
+	query := `
+		SELECT ` + gameColumns + `
+		FROM games
+		WHERE status = 'finished' AND (
+			json_extract(players, '$[0].ID') = ?1 OR json_extract(players, '$[1].ID') = ?1
+		)
+	`
+
+	games, err := r.queryGames(query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []domain.GameResult
+	for _, game := range games {
+		results = append(results, game.Result())
+	}
+
+	archived, err := archivedResultsByUser(r.db, userID)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, archived...)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].FinishedAt.Before(results[j].FinishedAt)
+	})
+
+	return results, nil
+}
+
+func (r *GameRepository) queryGames(query string, args ...any) ([]*domain.Game, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []*domain.Game
+	for rows.Next() {
+		game, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// gameMoves возвращает ходы игры для колонки moves: пустой список вместо NULL.
+func gameMoves(game *domain.Game) []domain.Coordinate {
+	if game.Moves == nil {
+		return []domain.Coordinate{}
+	}
+	return game.Moves
+}
+
+// winnerID возвращает значение колонки winner_id: NULL, пока у игры нет победителя.
+func winnerID(game *domain.Game) *string {
+	if winner := game.Winner(); winner != nil {
+		return &winner.ID
+	}
+	return nil
+}
+
+// gameWinnerID возвращает победителя для результата турнирной партии: пустую строку при ничьей.
+func gameWinnerID(game *domain.Game) string {
+	if winner := game.Winner(); winner != nil {
+		return winner.ID
+	}
+	return ""
+}
+
+const gameColumns = `id, board, players, status, rated, chat_id, inline_message_id, moves, timed_out_by, version, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanGame(row rowScanner, extra ...any) (*domain.Game, error) {
+	var game domain.Game
+	var boardJSON, playersJSON, movesJSON string
+
+	dest := []any{
+		&game.ID,
+		&boardJSON,
+		&playersJSON,
+		&game.Status,
+		&game.Rated,
+		&game.ChatID,
+		&game.InlineMessageID,
+		&movesJSON,
+		&game.TimedOutBy,
+		&game.Version,
+		&game.CreatedAt,
+		&game.UpdatedAt,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(boardJSON), &game.Board)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(playersJSON), &game.Players)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(movesJSON), &game.Moves)
+	if err != nil {
+		return nil, err
+	}
+
+	return &game, nil
+}
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+const seasonColumns = `id, starts_at, ends_at, archived_at`
+
+type SeasonRepository struct {
+	db *sql.DB
+}
+
+func NewSeasonRepository(db *sql.DB) *SeasonRepository {
+	return &SeasonRepository{db: db}
+}
+
+func (r *SeasonRepository) Current() (*domain.Season, error) {
+	row := r.db.QueryRow(`SELECT ` + seasonColumns + ` FROM seasons WHERE archived_at IS NULL ORDER BY id DESC LIMIT 1`)
+
+	s, err := scanSeason(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSeasonNotFound
+	}
+	return s, err
+}
+
+func (r *SeasonRepository) GetByID(id int) (*domain.Season, error) {
+	row := r.db.QueryRow(`SELECT `+seasonColumns+` FROM seasons WHERE id = ?`, id)
+
+	s, err := scanSeason(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrSeasonNotFound
+	}
+	return s, err
+}
+
+func (r *SeasonRepository) Create(s *domain.Season) error {
+	_, err := r.db.Exec(`INSERT INTO seasons (id, starts_at, ends_at) VALUES (?, ?, ?)`,
+		s.ID, s.StartsAt.UTC(), s.EndsAt.UTC())
+	return err
+}
+
+func (r *SeasonRepository) Rollover(finished, next *domain.Season, notify domain.SeasonNotifyFunc) ([]domain.SeasonStanding, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE seasons SET archived_at = ? WHERE id = ? AND archived_at IS NULL`,
+		time.Now().UTC(), finished.ID)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, domain.ErrSeasonClosed
+	}
+
+	standings, err := finalStandings(tx, finished)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO season_standings (season_id, user_id, display_name, rank, rating)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	for _, st := range standings {
+		if _, err := tx.Exec(query, st.SeasonID, st.UserID, st.DisplayName, st.Rank, st.Rating); err != nil {
+			return nil, err
+		}
+	}
+
+	query = `UPDATE users SET rating = ?1 + (rating - ?1) * ?2 / 100`
+	if _, err := tx.Exec(query, domain.DefaultRating, domain.SeasonCarryOverPercent); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO seasons (id, starts_at, ends_at) VALUES (?, ?, ?)`,
+		next.ID, next.StartsAt.UTC(), next.EndsAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := notify(standings)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertNotifications(tx, notifications); err != nil {
+		return nil, err
+	}
+
+	return standings, tx.Commit()
+}
+
+// finalStandings строит итоговую таблицу сезона: игроки, сыгравшие в нём рейтинговые игры, по рейтингу.
+func finalStandings(tx *sql.Tx, season *domain.Season) ([]domain.SeasonStanding, error) {
+	query := `
+		SELECT u.id, u.display_name, u.rating
+		FROM users u
+		WHERE EXISTS (SELECT 1 FROM rating_history h WHERE h.user_id = u.id AND h.created_at >= ?)
+		ORDER BY u.rating DESC, u.id
+	`
+	rows, err := tx.Query(query, season.StartsAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []domain.SeasonStanding
+	for rows.Next() {
+		st := domain.SeasonStanding{SeasonID: season.ID, Rank: len(standings) + 1}
+		if err := rows.Scan(&st.UserID, &st.DisplayName, &st.Rating); err != nil {
+			return nil, err
+		}
+		standings = append(standings, st)
+	}
+
+	return standings, rows.Err()
+}
+
+func (r *SeasonRepository) GetStandings(seasonID, limit int) ([]domain.SeasonStanding, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `
+		SELECT season_id, user_id, display_name, rank, rating
+		FROM season_standings
+		WHERE season_id = ?
+		ORDER BY rank
+		LIMIT ?
+	`
+	return querySeasonStandings(r.db.Query(query, seasonID, limit))
+}
+
+func (r *SeasonRepository) GetUserStandings(userID string) ([]domain.SeasonStanding, error) {
+	query := `
+		SELECT season_id, user_id, display_name, rank, rating
+		FROM season_standings
+		WHERE user_id = ?
+		ORDER BY season_id DESC
+	`
+	return querySeasonStandings(r.db.Query(query, userID))
+}
+
+func querySeasonStandings(rows *sql.Rows, err error) ([]domain.SeasonStanding, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var standings []domain.SeasonStanding
+	for rows.Next() {
+		var st domain.SeasonStanding
+		if err := rows.Scan(&st.SeasonID, &st.UserID, &st.DisplayName, &st.Rank, &st.Rating); err != nil {
+			return nil, err
+		}
+		standings = append(standings, st)
+	}
+
+	return standings, rows.Err()
+}
+
+func scanSeason(row rowScanner) (*domain.Season, error) {
+	var s domain.Season
+	var archivedAt sql.NullTime
+
+	if err := row.Scan(&s.ID, &s.StartsAt, &s.EndsAt, &archivedAt); err != nil {
+		return nil, err
+	}
+	if archivedAt.Valid {
+		s.ArchivedAt = &archivedAt.Time
+	}
+	return &s, nil
+}
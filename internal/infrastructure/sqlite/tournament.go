@@ -0,0 +1,329 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+const tournamentColumns = `id, name, format, status, organizer_id, organizer_name, starts_at, round, rounds, duration_seconds, created_at, finished_at`
+
+type TournamentRepository struct {
+	db *sql.DB
+}
+
+func NewTournamentRepository(db *sql.DB) *TournamentRepository {
+	return &TournamentRepository{db: db}
+}
+
+func (r *TournamentRepository) Create(t *domain.Tournament) error {
+	query := `
+		INSERT INTO tournaments (id, name, format, status, organizer_id, organizer_name, starts_at, round, rounds, duration_seconds, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Exec(query,
+		t.ID, t.Name, t.Format, t.Status, t.OrganizerID, t.OrganizerName, t.StartsAt.UTC(), t.Round, t.Rounds, int(t.Duration.Seconds()), t.CreatedAt.UTC())
+	return err
+}
+
+func (r *TournamentRepository) GetByID(id string) (*domain.Tournament, error) {
+	row := r.db.QueryRow(`SELECT `+tournamentColumns+` FROM tournaments WHERE id = ?`, id)
+
+	t, err := scanTournament(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTournamentNotFound
+	}
+	return t, err
+}
+
+func (r *TournamentRepository) ListByStatus(statuses ...domain.TournamentStatus) ([]*domain.Tournament, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(statuses))
+	args := make([]any, len(statuses))
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args[i] = status
+	}
+
+	query := `SELECT ` + tournamentColumns + ` FROM tournaments WHERE status IN (` + strings.Join(placeholders, ", ") + `) ORDER BY starts_at`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tournaments []*domain.Tournament
+	for rows.Next() {
+		t, err := scanTournament(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, t)
+	}
+
+	return tournaments, rows.Err()
+}
+
+func (r *TournamentRepository) AddPlayer(p domain.TournamentPlayer, limit int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status domain.TournamentStatus
+	err = tx.QueryRow(`SELECT status FROM tournaments WHERE id = ?`, p.TournamentID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrTournamentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.TournamentRegistration {
+		return domain.ErrRegistrationClosed
+	}
+
+	var count int
+	var registered bool
+	query := `
+		SELECT COUNT(*), COALESCE(MAX(user_id = ?), 0)
+		FROM tournament_players
+		WHERE tournament_id = ?
+	`
+	if err := tx.QueryRow(query, p.UserID, p.TournamentID).Scan(&count, &registered); err != nil {
+		return err
+	}
+	if registered {
+		return domain.ErrAlreadyRegistered
+	}
+	if count >= limit {
+		return domain.ErrTournamentFull
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tournament_players (tournament_id, user_id, user_name, seed, joined_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, p.TournamentID, p.UserID, p.UserName, p.Seed, p.JoinedAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TournamentRepository) RemovePlayer(tournamentID, userID string) (bool, error) {
+	query := `
+		DELETE FROM tournament_players
+		WHERE tournament_id = ? AND user_id = ?
+			AND EXISTS (SELECT 1 FROM tournaments WHERE id = ? AND status = 'registration')
+	`
+	result, err := r.db.Exec(query, tournamentID, userID, tournamentID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *TournamentRepository) GetPlayers(tournamentID string) ([]domain.TournamentPlayer, error) {
+	query := `
+		SELECT tournament_id, user_id, user_name, seed, joined_at
+		FROM tournament_players
+		WHERE tournament_id = ?
+		ORDER BY seed, joined_at
+	`
+	rows, err := r.db.Query(query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var players []domain.TournamentPlayer
+	for rows.Next() {
+		var p domain.TournamentPlayer
+		if err := rows.Scan(&p.TournamentID, &p.UserID, &p.UserName, &p.Seed, &p.JoinedAt); err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+	}
+
+	return players, rows.Err()
+}
+
+func (r *TournamentRepository) SetSeeds(players []domain.TournamentPlayer) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range players {
+		_, err := tx.Exec(`UPDATE tournament_players SET seed = ? WHERE tournament_id = ? AND user_id = ?`,
+			p.Seed, p.TournamentID, p.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TournamentRepository) GetGames(tournamentID string) ([]domain.TournamentGame, error) {
+	query := `
+		SELECT id, tournament_id, round, COALESCE(game_id, ''), first_id, second_id, winner_id, finished, created_at
+		FROM tournament_games
+		WHERE tournament_id = ?
+		ORDER BY round, created_at, id
+	`
+	rows, err := r.db.Query(query, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []domain.TournamentGame
+	for rows.Next() {
+		var g domain.TournamentGame
+		err := rows.Scan(&g.ID, &g.TournamentID, &g.Round, &g.GameID, &g.FirstID, &g.SecondID, &g.WinnerID, &g.Finished, &g.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+	}
+
+	return games, rows.Err()
+}
+
+func (r *TournamentRepository) StartRound(t *domain.Tournament, fromRound int, games []domain.TournamentGame, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE tournaments
+		SET status = ?, round = ?, rounds = ?
+		WHERE id = ? AND round = ?
+	`, t.Status, t.Round, t.Rounds, t.ID, fromRound)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTournamentRoundDone
+	}
+
+	query := `
+		INSERT INTO tournament_games (id, tournament_id, round, game_id, first_id, second_id, winner_id, finished, created_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?)
+	`
+	for _, g := range games {
+		_, err := tx.Exec(query,
+			g.ID, g.TournamentID, g.Round, g.GameID, g.FirstID, g.SecondID, g.WinnerID, g.Finished, g.CreatedAt.UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TournamentRepository) RecordResult(gameID, winnerID string) (string, error) {
+	tournamentID, err := recordTournamentResult(r.db.QueryRow, gameID, winnerID)
+	if err != nil || tournamentID != "" {
+		return tournamentID, err
+	}
+
+	err = r.db.QueryRow(`SELECT tournament_id FROM tournament_games WHERE game_id = ?`, gameID).Scan(&tournamentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return tournamentID, err
+}
+
+// recordTournamentResult отмечает результат ещё не засчитанной турнирной партии через queryRow -
+// запрос к базе или к открытой транзакции. Для обычной игры или уже засчитанной партии
+// возвращает пустую строку.
+func recordTournamentResult(queryRow func(query string, args ...any) *sql.Row, gameID, winnerID string) (string, error) {
+	query := `
+		UPDATE tournament_games
+		SET winner_id = ?, finished = TRUE
+		WHERE game_id = ? AND NOT finished
+		RETURNING tournament_id
+	`
+
+	var tournamentID string
+	err := queryRow(query, winnerID, gameID).Scan(&tournamentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return tournamentID, err
+}
+
+func (r *TournamentRepository) Finish(t *domain.Tournament, notifications ...domain.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE tournaments
+		SET status = ?, finished_at = ?
+		WHERE id = ? AND status IN ('registration', 'running')
+	`, t.Status, t.FinishedAt.UTC(), t.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTournamentRoundDone
+	}
+
+	if err := insertNotifications(tx, notifications); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanTournament(row rowScanner) (*domain.Tournament, error) {
+	var t domain.Tournament
+	var finishedAt sql.NullTime
+	var duration int
+
+	err := row.Scan(&t.ID, &t.Name, &t.Format, &t.Status, &t.OrganizerID, &t.OrganizerName,
+		&t.StartsAt, &t.Round, &t.Rounds, &duration, &t.CreatedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Duration = time.Duration(duration) * time.Second
+	if finishedAt.Valid {
+		t.FinishedAt = &finishedAt.Time
+	}
+
+	return &t, nil
+}
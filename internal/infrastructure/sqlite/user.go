@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/tictactoe/internal/domain"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Upsert(user *domain.User) error {
+	if user.Settings == nil {
+		user.Settings = map[string]string{}
+	}
+
+	settings, err := json.Marshal(user.Settings)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO users (id, display_name, username, language, settings, created_at, last_seen_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6)
+		ON CONFLICT (id) DO UPDATE SET
+			display_name = COALESCE(NULLIF(excluded.display_name, ''), users.display_name),
+			username = COALESCE(NULLIF(excluded.username, ''), users.username),
+			language = COALESCE(NULLIF(excluded.language, ''), users.language),
+			last_seen_at = excluded.last_seen_at
+		RETURNING ` + userColumns + `
+	`
+
+	saved, err := scanUser(r.db.QueryRow(query,
+		user.ID, user.DisplayName, user.Username, user.Language, string(settings), user.LastSeenAt.UTC(),
+	))
+	if err != nil {
+		return err
+	}
+
+	*user = *saved
+	return nil
+}
+
+func (r *UserRepository) GetByID(id string) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ?
+	`
+
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepository) GetByUsername(username string) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE username = ? COLLATE NOCASE
+		ORDER BY last_seen_at DESC
+		LIMIT 1
+	`
+
+	user, err := scanUser(r.db.QueryRow(query, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, err
+}
+
+func (r *UserRepository) GetByIDs(ids []string) (map[string]*domain.User, error) {
+	return getUsersByIDs(r.db.Query, ids)
+}
+
+// getUsersByIDs читает профили через query - запрос к базе или к открытой транзакции.
+func getUsersByIDs(query func(query string, args ...any) (*sql.Rows, error), ids []string) (map[string]*domain.User, error) {
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := query(`
+		SELECT `+userColumns+`
+		FROM users
+		WHERE id IN (SELECT value FROM json_each(?))
+	`, string(idsJSON))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[string]*domain.User, len(ids))
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+
+	return users, rows.Err()
+}
+
+const userColumns = `id, display_name, username, language, settings, created_at, last_seen_at, rating, rated_games`
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	var settingsJSON string
+
+	err := row.Scan(
+		&user.ID,
+		&user.DisplayName,
+		&user.Username,
+		&user.Language,
+		&settingsJSON,
+		&user.CreatedAt,
+		&user.LastSeenAt,
+		&user.Rating,
+		&user.RatedGames,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(settingsJSON), &user.Settings); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -3,7 +3,9 @@ package http
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -13,11 +15,42 @@ import (
 )
 
 type CommandHandler struct {
-	gameService *app.GameService
+	gameService        *app.GameService
+	userService        *app.UserService
+	statsService       *app.StatsService
+	adminService       *app.AdminService
+	matchmakingService *app.MatchmakingService
+	challengeService   *app.ChallengeService
+	friendService      *app.FriendService
+	blockService       *app.BlockService
+	tournamentService  *app.TournamentService
+	seasonService      *app.SeasonService
 }
 
-func NewCommandHandler(gameService *app.GameService) *CommandHandler {
-	return &CommandHandler{gameService: gameService}
+func NewCommandHandler(
+	gameService *app.GameService,
+	userService *app.UserService,
+	statsService *app.StatsService,
+	adminService *app.AdminService,
+	matchmakingService *app.MatchmakingService,
+	challengeService *app.ChallengeService,
+	friendService *app.FriendService,
+	blockService *app.BlockService,
+	tournamentService *app.TournamentService,
+	seasonService *app.SeasonService,
+) *CommandHandler {
+	return &CommandHandler{
+		gameService:        gameService,
+		userService:        userService,
+		statsService:       statsService,
+		adminService:       adminService,
+		matchmakingService: matchmakingService,
+		challengeService:   challengeService,
+		friendService:      friendService,
+		blockService:       blockService,
+		tournamentService:  tournamentService,
+		seasonService:      seasonService,
+	}
 }
 
 func (h *CommandHandler) RegisterRoutes(r chi.Router) {
@@ -28,19 +61,31 @@ func (h *CommandHandler) RegisterRoutes(r chi.Router) {
 func (h *CommandHandler) HandleCommand(w http.ResponseWriter, r *http.Request) {
 	var msg dto.IncomingMessage
 	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
-		http.Error(w, "неверный формат сообщения", http.StatusBadRequest)
+		writeErrorResponse(w, http.StatusBadRequest, codeInvalidRequest, "неверный формат сообщения")
 		return
 	}
+	// бот старой версии может прислать идентификатор в формате chat_<id>
+	msg.UserID = domain.NormalizeUserID(msg.UserID)
 
 	command := h.getCommand(msg)
 	if command == "" {
-		http.Error(w, "отсутствует команда", http.StatusBadRequest)
+		writeErrorResponse(w, http.StatusBadRequest, codeMissingCommand, "отсутствует команда")
 		return
 	}
 
-	response, err := h.executeCommand(command, msg.UserID, msg.UserName)
+	_, err := h.userService.Touch(dto.TouchUserRequest{
+		UserID:      msg.UserID,
+		DisplayName: msg.UserName,
+		Username:    msg.Username,
+		Language:    msg.LanguageCode,
+	})
+	if err != nil {
+		log.Printf("Не удалось обновить профиль %s: %v", msg.UserID, err)
+	}
+
+	response, err := h.executeCommand(command, msg.UserID, msg.UserName, groupChatID(msg), msg.InlineMessageID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -59,13 +104,15 @@ func (h *CommandHandler) HandleCommand(w http.ResponseWriter, r *http.Request) {
 func (h *CommandHandler) HandleNotify(w http.ResponseWriter, r *http.Request) {
 	var msg dto.IncomingMessage
 	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
-		http.Error(w, "неверный формат сообщения", http.StatusBadRequest)
+		writeErrorResponse(w, http.StatusBadRequest, codeInvalidRequest, "неверный формат сообщения")
 		return
 	}
+	// бот старой версии может прислать идентификатор в формате chat_<id>
+	msg.UserID = domain.NormalizeUserID(msg.UserID)
 
 	command := h.getCommand(msg)
 	if command == "" {
-		http.Error(w, "отсутствует команда", http.StatusBadRequest)
+		writeErrorResponse(w, http.StatusBadRequest, codeMissingCommand, "отсутствует команда")
 		return
 	}
 
@@ -77,7 +124,7 @@ func (h *CommandHandler) HandleNotify(w http.ResponseWriter, r *http.Request) {
 		gameID := strings.TrimPrefix(command, "/join ")
 		game, err := h.gameService.GetGameByID(gameID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		response = h.gameService.GetGameNotifications(game)
@@ -85,24 +132,24 @@ func (h *CommandHandler) HandleNotify(w http.ResponseWriter, r *http.Request) {
 	case strings.HasPrefix(command, "/move "):
 		parts := strings.Split(command, " ")
 		if len(parts) != 3 {
-			http.Error(w, domain.ErrInvalidMove.Error(), http.StatusBadRequest)
+			writeError(w, domain.ErrInvalidMove)
 			return
 		}
 		gameID := parts[1]
 		game, err := h.gameService.GetGameByID(gameID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 		response = h.gameService.GetGameNotifications(game)
 
 	default:
-		http.Error(w, "команда не поддерживает push-уведомления", http.StatusBadRequest)
+		writeErrorResponse(w, http.StatusBadRequest, codeUnsupportedCommand, "команда не поддерживает push-уведомления")
 		return
 	}
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -129,13 +176,121 @@ func (h *CommandHandler) getCommand(msg dto.IncomingMessage) string {
 	return ""
 }
 
-func (h *CommandHandler) executeCommand(command, userID, userName string) (interface{}, error) {
+// groupChatID возвращает идентификатор группового чата, из которого пришла команда, или пустую строку для личного.
+func groupChatID(msg dto.IncomingMessage) string {
+	if msg.ChatType == "group" || msg.ChatType == "supergroup" {
+		return msg.ChatID
+	}
+	return ""
+}
+
+// messageForUser выбирает из ответа игры сообщение для пользователя: его доску или общую доску игры.
+func messageForUser(response *dto.OutgoingMessages, userID string) (*dto.OutgoingMessage, error) {
+	for _, msg := range response.Messages {
+		if msg.UserID == userID || msg.ChatID != "" || msg.InlineMessageID != "" {
+			return &msg, nil
+		}
+	}
+	return nil, fmt.Errorf("сообщение для пользователя не найдено")
+}
+
+// parseListRequest разбирает аргументы /list: номер страницы, new - сначала новые,
+// near - только соперники близкого рейтинга. Неизвестные аргументы игнорируются.
+func parseListRequest(userID, args string) dto.ListGamesRequest {
+	req := dto.ListGamesRequest{UserID: userID, Page: 1}
+	for _, arg := range strings.Fields(args) {
+		switch arg {
+		case "new":
+			req.NewestFirst = true
+		case "near":
+			req.NearRating = true
+		default:
+			if page, err := strconv.Atoi(arg); err == nil {
+				req.Page = page
+			}
+		}
+	}
+	return req
+}
+
+// executeCommand выполняет команду. inlineMessageID задан для команд из inline-сообщения: новая игра
+// привязывается к нему, а /join без номера присоединяет к игре этого сообщения.
+func (h *CommandHandler) executeCommand(command, userID, userName, chatID, inlineMessageID string) (interface{}, error) {
 	switch {
 	case command == "/new":
-		return h.gameService.CreateGame(dto.CreateGameRequest{UserID: userID, UserName: userName})
+		return h.gameService.ChooseGameType(userID), nil
+
+	case command == "/new rated", command == "/new casual":
+		return h.gameService.CreateGame(dto.CreateGameRequest{
+			UserID:          userID,
+			UserName:        userName,
+			ChatID:          chatID,
+			InlineMessageID: inlineMessageID,
+			Rated:           command == "/new rated",
+		})
+
+	case command == "/list", strings.HasPrefix(command, "/list "):
+		return h.gameService.ListGames(parseListRequest(userID, strings.TrimPrefix(command, "/list")))
+
+	case command == "/play":
+		return h.matchmakingService.Play(userID, userName)
+
+	case command == "/play cancel":
+		return h.matchmakingService.Cancel(userID)
+
+	case strings.HasPrefix(command, "/challenge "):
+		target := strings.TrimSpace(strings.TrimPrefix(command, "/challenge "))
+		return h.challengeService.Challenge(userID, userName, target)
+
+	case strings.HasPrefix(command, "/accept "):
+		challengeID := strings.TrimSpace(strings.TrimPrefix(command, "/accept "))
+		return h.challengeService.Accept(userID, userName, challengeID)
+
+	case strings.HasPrefix(command, "/decline "):
+		challengeID := strings.TrimSpace(strings.TrimPrefix(command, "/decline "))
+		return h.challengeService.Decline(userID, challengeID)
+
+	case command == "/friends":
+		return h.friendService.ShowFriends(userID)
+
+	case strings.HasPrefix(command, "/friends add "):
+		return h.friendService.AddFriend(userID, strings.TrimSpace(strings.TrimPrefix(command, "/friends add ")))
 
-	case command == "/list":
-		return h.gameService.ListGames(userID)
+	case strings.HasPrefix(command, "/friends remove "):
+		return h.friendService.RemoveFriend(userID, strings.TrimSpace(strings.TrimPrefix(command, "/friends remove ")))
+
+	case command == "/opponents":
+		return h.friendService.ShowRecentOpponents(userID)
+
+	case strings.HasPrefix(command, "/block "):
+		return h.blockService.Block(userID, strings.TrimSpace(strings.TrimPrefix(command, "/block ")))
+
+	case strings.HasPrefix(command, "/unblock "):
+		return h.blockService.Unblock(userID, strings.TrimSpace(strings.TrimPrefix(command, "/unblock ")))
+
+	case command == "/blocked":
+		return h.blockService.ShowBlocked(userID)
+
+	case command == "/tournaments", command == "/tournament", command == "/tournament new":
+		return h.tournamentService.List(userID)
+
+	case strings.HasPrefix(command, "/tournament new "):
+		return h.tournamentService.Create(userID, userName, strings.TrimPrefix(command, "/tournament new "))
+
+	case strings.HasPrefix(command, "/tournament join "):
+		return h.tournamentService.Join(userID, userName, strings.TrimSpace(strings.TrimPrefix(command, "/tournament join ")))
+
+	case strings.HasPrefix(command, "/tournament leave "):
+		return h.tournamentService.Leave(userID, strings.TrimSpace(strings.TrimPrefix(command, "/tournament leave ")))
+
+	case strings.HasPrefix(command, "/tournament "):
+		return h.tournamentService.Show(userID, strings.TrimSpace(strings.TrimPrefix(command, "/tournament ")))
+
+	case command == "/season":
+		return h.seasonService.Show(userID, "")
+
+	case strings.HasPrefix(command, "/season "):
+		return h.seasonService.Show(userID, strings.TrimPrefix(command, "/season "))
 
 	case command == "/start":
 		return h.gameService.ShowHelp(userID), nil
@@ -143,22 +298,19 @@ func (h *CommandHandler) executeCommand(command, userID, userName string) (inter
 	case command == "/help", command == "":
 		return h.gameService.ShowHelp(userID), nil
 
-	case strings.HasPrefix(command, "/join "):
-		gameID := strings.TrimPrefix(command, "/join ")
+	case strings.HasPrefix(command, "/join "), command == "/join" && inlineMessageID != "":
+		gameID := strings.TrimSpace(strings.TrimPrefix(command, "/join"))
 		response, err := h.gameService.JoinGame(dto.JoinGameRequest{
-			UserID:   userID,
-			UserName: userName,
-			GameID:   gameID,
+			UserID:          userID,
+			UserName:        userName,
+			ChatID:          chatID,
+			InlineMessageID: inlineMessageID,
+			GameID:          gameID,
 		})
 		if err != nil {
 			return nil, err
 		}
-		for _, msg := range response.Messages {
-			if msg.UserID == userID {
-				return &msg, nil
-			}
-		}
-		return nil, fmt.Errorf("сообщение для пользователя не найдено")
+		return messageForUser(response, userID)
 
 	case strings.HasPrefix(command, "/move "):
 		parts := strings.Split(command, " ")
@@ -174,12 +326,7 @@ func (h *CommandHandler) executeCommand(command, userID, userName string) (inter
 		if err != nil {
 			return nil, err
 		}
-		for _, msg := range response.Messages {
-			if msg.UserID == userID {
-				return &msg, nil
-			}
-		}
-		return nil, fmt.Errorf("сообщение для пользователя не найдено")
+		return messageForUser(response, userID)
 
 	case strings.HasPrefix(command, "/game "):
 		gameID := strings.TrimPrefix(command, "/game ")
@@ -192,6 +339,28 @@ func (h *CommandHandler) executeCommand(command, userID, userName string) (inter
 	case command == "/mygame":
 		return h.gameService.GetActiveGame(userID)
 
+	case command == "/stats":
+		return h.statsService.ShowStats(userID, "")
+
+	case strings.HasPrefix(command, "/stats "):
+		return h.statsService.ShowStats(userID, strings.TrimSpace(strings.TrimPrefix(command, "/stats ")))
+
+	case command == "/profile":
+		return h.statsService.ShowProfile(userID, "")
+
+	case strings.HasPrefix(command, "/profile "):
+		return h.statsService.ShowProfile(userID, strings.TrimSpace(strings.TrimPrefix(command, "/profile ")))
+
+	case command == "/top":
+		return h.statsService.ShowLeaderboard(userID, chatID, "")
+
+	case strings.HasPrefix(command, "/top "):
+		return h.statsService.ShowLeaderboard(userID, chatID, strings.TrimPrefix(command, "/top "))
+
+	case strings.HasPrefix(command, "/admin restore "):
+		gameID := strings.TrimSpace(strings.TrimPrefix(command, "/admin restore "))
+		return h.adminService.RestoreGame(userID, gameID)
+
 	default:
 		return h.gameService.ShowHelp(userID), nil
 	}
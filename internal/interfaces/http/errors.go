@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tictactoe/internal/domain"
+	"github.com/tictactoe/internal/dto"
+)
+
+const (
+	codeInvalidRequest     = "invalid_request"
+	codeMissingCommand     = "missing_command"
+	codeUnsupportedCommand = "unsupported_command"
+	codeInternal           = "internal_error"
+)
+
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var errorMappings = []errorMapping{
+	{domain.ErrGameNotFound, http.StatusNotFound, "game_not_found"},
+	{domain.ErrGameNotActive, http.StatusConflict, "game_not_active"},
+	{domain.ErrGameFinished, http.StatusConflict, "game_finished"},
+	{domain.ErrConcurrentUpdate, http.StatusConflict, "concurrent_update"},
+	{domain.ErrNotPlayerTurn, http.StatusConflict, "not_player_turn"},
+	{domain.ErrAlreadyInGame, http.StatusConflict, "already_in_game"},
+	{domain.ErrCannotJoin, http.StatusConflict, "cannot_join"},
+	{domain.ErrNotParticipant, http.StatusForbidden, "not_participant"},
+	{domain.ErrInvalidMove, http.StatusUnprocessableEntity, "invalid_move"},
+	{domain.ErrInvalidCoordinate, http.StatusBadRequest, "invalid_coordinate"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{domain.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{domain.ErrChallengeNotFound, http.StatusNotFound, "challenge_not_found"},
+	{domain.ErrChallengeSelf, http.StatusUnprocessableEntity, "challenge_self"},
+	{domain.ErrChallengeExpired, http.StatusGone, "challenge_expired"},
+	{domain.ErrChallengeClosed, http.StatusConflict, "challenge_closed"},
+	{domain.ErrFriendSelf, http.StatusUnprocessableEntity, "friend_self"},
+	{domain.ErrBlockSelf, http.StatusUnprocessableEntity, "block_self"},
+	{domain.ErrUserBlocked, http.StatusForbidden, "user_blocked"},
+	{domain.ErrTournamentNotFound, http.StatusNotFound, "tournament_not_found"},
+	{domain.ErrInvalidTournament, http.StatusUnprocessableEntity, "invalid_tournament"},
+	{domain.ErrRegistrationClosed, http.StatusConflict, "registration_closed"},
+	{domain.ErrTournamentFull, http.StatusConflict, "tournament_full"},
+	{domain.ErrAlreadyRegistered, http.StatusConflict, "already_registered"},
+	{domain.ErrNotRegistered, http.StatusConflict, "not_registered"},
+	{domain.ErrTournamentRoundDone, http.StatusConflict, "tournament_round_done"},
+	{domain.ErrSeasonNotFound, http.StatusNotFound, "season_not_found"},
+	{domain.ErrSeasonClosed, http.StatusConflict, "season_closed"},
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			writeErrorResponse(w, m.status, m.code, m.err.Error())
+			return
+		}
+	}
+
+	log.Printf("Внутренняя ошибка: %v", err)
+	writeErrorResponse(w, http.StatusInternalServerError, codeInternal, "внутренняя ошибка сервера")
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.NewErrorResponse(code, message))
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS game_events (
+    game_id VARCHAR(36) NOT NULL,
+    seq INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (game_id, seq)
+);
+
+CREATE TABLE IF NOT EXISTS game_snapshots (
+    game_id VARCHAR(36) NOT NULL,
+    seq INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (game_id, seq)
+);
+
+-- +goose Down
+DROP TABLE game_snapshots;
+DROP TABLE game_events;
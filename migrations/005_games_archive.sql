@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS restored_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS games_archive (
+    id VARCHAR(36) PRIMARY KEY,
+    player_x_id VARCHAR(64) NOT NULL,
+    player_o_id VARCHAR(64) NOT NULL,
+    winner_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL,
+    data BYTEA NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_games_archive_player_x ON games_archive(player_x_id);
+CREATE INDEX IF NOT EXISTS idx_games_archive_player_o ON games_archive(player_o_id);
+CREATE INDEX IF NOT EXISTS idx_games_archive_finished_at ON games_archive(finished_at);
+
+-- +goose Down
+DROP TABLE games_archive;
+ALTER TABLE games DROP COLUMN restored_at;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(64) PRIMARY KEY,
+    display_name VARCHAR(255) NOT NULL DEFAULT '',
+    username VARCHAR(64) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT '',
+    settings JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(LOWER(username));
+
+INSERT INTO users (id, display_name, created_at, last_seen_at)
+SELECT p->>'ID', MAX(p->>'Name'), MIN(g.created_at), MAX(g.updated_at)
+FROM games g, jsonb_array_elements(g.players) p
+WHERE COALESCE(p->>'ID', '') <> ''
+GROUP BY p->>'ID'
+ON CONFLICT (id) DO NOTHING;
+
+-- +goose Down
+DROP TABLE users;
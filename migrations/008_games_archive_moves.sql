@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE games_archive ADD COLUMN IF NOT EXISTS moves INTEGER;
+
+-- +goose Down
+ALTER TABLE games_archive DROP COLUMN moves;
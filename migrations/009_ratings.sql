@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS rated BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS rating INTEGER NOT NULL DEFAULT 1200;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS rated_games INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS rating_history (
+    game_id VARCHAR(36) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    rating_before INTEGER NOT NULL,
+    rating_after INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (game_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_history_user ON rating_history(user_id, created_at);
+
+-- +goose Down
+DROP TABLE rating_history;
+ALTER TABLE users DROP COLUMN rated_games;
+ALTER TABLE users DROP COLUMN rating;
+ALTER TABLE games DROP COLUMN rated;
@@ -0,0 +1,40 @@
+-- +goose Up
+ALTER TABLE games ADD COLUMN IF NOT EXISTS winner_id VARCHAR(64);
+
+UPDATE games g
+SET winner_id = (
+    SELECT p->>'ID'
+    FROM jsonb_array_elements(g.players) p
+    WHERE p->>'Symbol' = (
+        SELECT l.a
+        FROM (VALUES
+            (g.board->0->>0, g.board->0->>1, g.board->0->>2),
+            (g.board->1->>0, g.board->1->>1, g.board->1->>2),
+            (g.board->2->>0, g.board->2->>1, g.board->2->>2),
+            (g.board->0->>0, g.board->1->>0, g.board->2->>0),
+            (g.board->0->>1, g.board->1->>1, g.board->2->>1),
+            (g.board->0->>2, g.board->1->>2, g.board->2->>2),
+            (g.board->0->>0, g.board->1->>1, g.board->2->>2),
+            (g.board->0->>2, g.board->1->>1, g.board->2->>0)
+        ) AS l(a, b, c)
+        WHERE l.a <> '' AND l.a = l.b AND l.b = l.c
+        LIMIT 1
+    )
+)
+WHERE g.status = 'finished' AND g.winner_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_games_finished_winner ON games(winner_id, updated_at) WHERE status = 'finished';
+CREATE INDEX IF NOT EXISTS idx_games_archive_winner ON games_archive(winner_id, finished_at);
+
+CREATE TABLE IF NOT EXISTS chat_members (
+    chat_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    last_played_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chat_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE chat_members;
+DROP INDEX IF EXISTS idx_games_archive_winner;
+DROP INDEX IF EXISTS idx_games_finished_winner;
+ALTER TABLE games DROP COLUMN winner_id;
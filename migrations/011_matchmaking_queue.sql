@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS matchmaking_queue (
+    user_id VARCHAR(64) PRIMARY KEY,
+    user_name VARCHAR(255) NOT NULL DEFAULT '',
+    rating INTEGER NOT NULL,
+    enqueued_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE matchmaking_queue;
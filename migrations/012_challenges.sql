@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS challenges (
+    id VARCHAR(36) PRIMARY KEY,
+    challenger_id VARCHAR(64) NOT NULL,
+    challenger_name VARCHAR(255) NOT NULL DEFAULT '',
+    target_id VARCHAR(64) NOT NULL,
+    target_name VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    game_id VARCHAR(36),
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_challenges_target ON challenges(target_id, status);
+
+-- +goose Down
+DROP TABLE challenges;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS friends (
+    user_id VARCHAR(64) NOT NULL,
+    friend_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, friend_id)
+);
+
+-- +goose Down
+DROP TABLE friends;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id VARCHAR(64) NOT NULL,
+    code VARCHAR(32) NOT NULL,
+    game_id VARCHAR(36) NOT NULL,
+    awarded_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_achievements_game ON user_achievements(game_id);
+
+-- +goose Down
+DROP TABLE user_achievements;
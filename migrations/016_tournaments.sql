@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS tournaments (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    format VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    organizer_id VARCHAR(64) NOT NULL,
+    organizer_name VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at TIMESTAMP NOT NULL,
+    round INTEGER NOT NULL DEFAULT 0,
+    rounds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_status ON tournaments(status, starts_at);
+
+CREATE TABLE IF NOT EXISTS tournament_players (
+    tournament_id VARCHAR(36) NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL,
+    user_name VARCHAR(255) NOT NULL DEFAULT '',
+    seed INTEGER NOT NULL DEFAULT 0,
+    joined_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tournament_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS tournament_games (
+    id VARCHAR(36) PRIMARY KEY,
+    tournament_id VARCHAR(36) NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    game_id VARCHAR(36) UNIQUE,
+    first_id VARCHAR(64) NOT NULL,
+    second_id VARCHAR(64) NOT NULL DEFAULT '',
+    winner_id VARCHAR(64) NOT NULL DEFAULT '',
+    finished BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_games_tournament ON tournament_games(tournament_id, round);
+
+-- +goose Down
+DROP TABLE tournament_games;
+DROP TABLE tournament_players;
+DROP TABLE tournaments;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Игра через inline-режим: доска живёт в inline-сообщении, в котором игру начали.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS inline_message_id VARCHAR(255) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_games_inline_message_id ON games(inline_message_id, created_at) WHERE inline_message_id <> '';
+
+-- +goose Down
+DROP INDEX idx_games_inline_message_id;
+ALTER TABLE games DROP COLUMN inline_message_id;
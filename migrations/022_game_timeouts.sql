@@ -0,0 +1,6 @@
+-- +goose Up
+-- Игрок, у которого вышло время на турнирную партию: по доске такой итог не определить.
+ALTER TABLE games ADD COLUMN IF NOT EXISTS timed_out_by VARCHAR(64) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE games DROP COLUMN timed_out_by;